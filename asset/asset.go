@@ -0,0 +1,111 @@
+// Package asset finds the files the application loads, like shaders, textures and scenes.
+//
+// Assets are named by logical names that use forward slashes on every platform and are relative to
+// the asset roots, e.g. "shaders/vertex/colors.glsl" or "textures/container2.png". A Library looks
+// for a name in each of its root directories in turn and, if it carries embedded assets, in those last.
+package asset
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Library resolves asset names against a list of directories and an optional embedded file system.
+type Library struct {
+	// Roots are the directories searched, in order.
+	Roots []string
+	// Embedded holds assets compiled into the binary. It is searched after the roots and may be nil.
+	Embedded fs.FS
+}
+
+// Default is the library used by the package level functions. It reads assets from the resources
+// directory in the working directory.
+var Default = &Library{Roots: []string{"resources"}}
+
+// Path returns the path on disk of the asset called name, from the first root containing it.
+// Embedded assets have no path.
+func (l *Library) Path(name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	for _, root := range l.Roots {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", l.notFound(name)
+}
+
+// Open opens the asset called name.
+func (l *Library) Open(name string) (io.ReadCloser, error) {
+	path, err := l.Path(name)
+	if err == nil {
+		return os.Open(path)
+	}
+
+	if l.Embedded != nil && fs.ValidPath(name) {
+		if file, err := l.Embedded.Open(name); err == nil {
+			return file, nil
+		}
+	}
+	return nil, err
+}
+
+// ReadFile reads the whole asset called name.
+func (l *Library) ReadFile(name string) ([]byte, error) {
+	file, err := l.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+// ModTime returns when the asset called name last changed. Embedded assets never change and report the zero time.
+func (l *Library) ModTime(name string) (time.Time, error) {
+	path, err := l.Path(name)
+	if err == nil {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return info.ModTime(), nil
+	}
+
+	if l.Embedded != nil && fs.ValidPath(name) {
+		if _, err := fs.Stat(l.Embedded, name); err == nil {
+			return time.Time{}, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func (l *Library) notFound(name string) error {
+	where := fmt.Sprintf("%v", l.Roots)
+	if l.Embedded != nil {
+		where += " or the embedded assets"
+	}
+	return &fs.PathError{Op: "open", Path: name, Err: fmt.Errorf("%w in %s", fs.ErrNotExist, where)}
+}
+
+// Open opens the asset called name from the Default library.
+func Open(name string) (io.ReadCloser, error) {
+	return Default.Open(name)
+}
+
+// ReadFile reads the asset called name from the Default library.
+func ReadFile(name string) ([]byte, error) {
+	return Default.ReadFile(name)
+}
+
+// ModTime returns when the asset called name in the Default library last changed.
+func ModTime(name string) (time.Time, error) {
+	return Default.ModTime(name)
+}
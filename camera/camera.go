@@ -0,0 +1,205 @@
+// Package camera holds the point of view the scene is drawn from. It only does the maths, input reaches it as plain
+// cursor positions and scroll offsets, so it works and can be tested without a window or an OpenGL context.
+package camera
+
+import (
+	"math"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// Projection is how a camera projects the scene onto the screen.
+type Projection int
+
+const (
+	// Perspective makes things smaller the further away they are, with the field of view of the camera.
+	Perspective Projection = iota
+	// Orthographic keeps sizes regardless of the distance, showing OrthoHeight world units from bottom to top.
+	Orthographic
+)
+
+// Camera is a point of view on the scene. Its orientation is held by Yaw, Pitch and Roll in degrees, CameraFront
+// and CameraUp follow from them: change the angles through Rotate, SetAngles, SetFront or LookAt to keep them in step.
+type Camera struct {
+	CameraPos   mgl.Vec3
+	CameraFront mgl.Vec3
+	CameraUp    mgl.Vec3
+	// WorldUp is the direction yaw turns around and pitch turns towards. Call SetWorldUp to change it.
+	WorldUp mgl.Vec3
+
+	// Yaw turns around WorldUp, 0 looks along +X and 90 along +Z when WorldUp is +Y. Pitch looks up,
+	// between -90 and 90 degrees, and Rotate stops it at 89. Roll tilts the camera clockwise around CameraFront.
+	Yaw         float64
+	Pitch       float64
+	Roll        float64
+	LastX       float64
+	LastY       float64
+	Fov         float64
+	Sensitivity float64
+	// MaxFov is the widest the field of view zooms out to.
+	MaxFov float64
+
+	Projection  Projection
+	OrthoHeight float64
+	// Near and Far are the distances of the clipping planes, Aspect is the width of the view divided by its height.
+	Near   float64
+	Far    float64
+	Aspect float64
+
+	FirstMouse bool
+}
+
+// New returns a perspective camera looking down at the origin from the side.
+func New() Camera {
+	c := Camera{}
+	c.CameraPos = mgl.Vec3{3.5, 1.5, 2.2}
+	c.WorldUp = mgl.Vec3{0, 1, 0}
+	// the angles come from the direction, so the first mouse move turns on from here
+	c.SetFront(mgl.Vec3{-0.85, -0.36, -0.36})
+
+	c.LastX = 0.0
+	c.LastY = 0.0
+	c.Fov = 45.0
+	c.MaxFov = 45.0
+
+	c.Sensitivity = 0.2
+	c.FirstMouse = true
+
+	c.Projection = Perspective
+	c.OrthoHeight = 10.0
+	c.Near = 0.1
+	c.Far = 100.0
+	c.Aspect = 1.0
+
+	return c
+}
+
+// ViewMatrix returns the matrix transforming world coordinates to the coordinates of the camera.
+func (c *Camera) ViewMatrix() mgl.Mat4 {
+	return mgl.LookAtV(c.CameraPos, c.CameraPos.Add(c.CameraFront), c.CameraUp)
+}
+
+// ProjectionMatrix returns the matrix projecting camera coordinates to clip space.
+func (c *Camera) ProjectionMatrix() mgl.Mat4 {
+	if c.Projection == Orthographic {
+		top := float32(c.OrthoHeight / 2)
+		right := top * float32(c.Aspect)
+		return mgl.Ortho(-right, right, -top, top, float32(c.Near), float32(c.Far))
+	}
+	return mgl.Perspective(mgl.DegToRad(float32(c.Fov)), float32(c.Aspect), float32(c.Near), float32(c.Far))
+}
+
+// ViewProjection returns the projection matrix times the view matrix, taking world coordinates to clip space.
+func (c *Camera) ViewProjection() mgl.Mat4 {
+	return c.ProjectionMatrix().Mul4(c.ViewMatrix())
+}
+
+// MouseCallback turns the camera by how far the cursor moved since the previous call, y grows downwards like
+// window coordinates. The first call after FirstMouse is set only remembers the position.
+func (c *Camera) MouseCallback(xpos float64, ypos float64) {
+	if c.FirstMouse {
+		c.LastX = xpos
+		c.LastY = ypos
+		c.FirstMouse = false
+	}
+
+	xoffset := xpos - c.LastX
+	yoffset := c.LastY - ypos
+	c.LastX = xpos
+	c.LastY = ypos
+
+	c.Rotate(xoffset*c.Sensitivity, yoffset*c.Sensitivity)
+}
+
+// Rotate turns the camera by yaw and pitch degrees. Pitch stops at 89 degrees up or down, a camera that was
+// pointed closer to straight up or down stays where it is instead of snapping back.
+func (c *Camera) Rotate(yaw, pitch float64) {
+	limit := math.Max(89, math.Abs(c.Pitch))
+	c.SetAngles(c.Yaw+yaw, math.Max(-limit, math.Min(limit, c.Pitch+pitch)), c.Roll)
+}
+
+// SetAngles points the camera with yaw, pitch and roll in degrees.
+func (c *Camera) SetAngles(yaw, pitch, roll float64) {
+	c.Yaw, c.Pitch, c.Roll = yaw, math.Max(-90, math.Min(90, pitch)), roll
+	c.updateVectors()
+}
+
+// SetFront points the camera along front, keeping its roll.
+func (c *Camera) SetFront(front mgl.Vec3) {
+	yaw, pitch := c.Angles(front)
+	c.SetAngles(yaw, pitch, c.Roll)
+}
+
+// LookAt turns the camera towards target.
+func (c *Camera) LookAt(target mgl.Vec3) {
+	if front := target.Sub(c.CameraPos); front.Len() > 0 {
+		c.SetFront(front)
+	}
+}
+
+// SetWorldUp changes the up direction of the world, keeping the direction the camera looks in.
+func (c *Camera) SetWorldUp(up mgl.Vec3) {
+	front := c.CameraFront
+	c.WorldUp = up.Normalize()
+	c.SetFront(front)
+}
+
+// Direction returns the unit vector yaw and pitch degrees point in, relative to WorldUp.
+func (c *Camera) Direction(yaw, pitch float64) mgl.Vec3 {
+	x, z := c.horizon()
+	yawRad, pitchRad := yaw*math.Pi/180, pitch*math.Pi/180
+	direction := x.Mul(float32(math.Cos(yawRad) * math.Cos(pitchRad))).
+		Add(z.Mul(float32(math.Sin(yawRad) * math.Cos(pitchRad)))).
+		Add(c.WorldUp.Normalize().Mul(float32(math.Sin(pitchRad))))
+	return direction.Normalize()
+}
+
+// Angles returns the yaw and pitch in degrees pointing along direction, the inverse of Direction.
+func (c *Camera) Angles(direction mgl.Vec3) (yaw, pitch float64) {
+	direction = direction.Normalize()
+	x, z := c.horizon()
+	up := c.WorldUp.Normalize()
+	pitch = math.Asin(float64(mgl.Clamp(direction.Dot(up), -1, 1))) * 180 / math.Pi
+	yaw = math.Atan2(float64(direction.Dot(z)), float64(direction.Dot(x))) * 180 / math.Pi
+	return yaw, pitch
+}
+
+// horizon returns the directions of yaw 0 and yaw 90 degrees, perpendicular to WorldUp. With +Y up they are
+// +X and +Z.
+func (c *Camera) horizon() (mgl.Vec3, mgl.Vec3) {
+	up := c.WorldUp.Normalize()
+	x := mgl.Vec3{1, 0, 0}
+	if math.Abs(float64(up.Dot(x))) > 0.99 {
+		x = mgl.Vec3{0, 0, -1}
+	}
+	x = x.Sub(up.Mul(up.Dot(x))).Normalize()
+	return x, x.Cross(up)
+}
+
+// updateVectors points CameraFront and CameraUp the way the angles say.
+func (c *Camera) updateVectors() {
+	c.CameraFront = c.Direction(c.Yaw, c.Pitch)
+
+	// the right side follows from the yaw alone, so it's still there looking straight up or down
+	right := c.Direction(c.Yaw+90, 0)
+	up := right.Cross(c.CameraFront)
+	roll := c.Roll * math.Pi / 180
+	c.CameraUp = up.Mul(float32(math.Cos(roll))).Add(right.Mul(float32(math.Sin(roll)))).Normalize()
+}
+
+// ScrollCallback zooms in by yoff steps, narrowing the field of view or showing less of the world.
+func (c *Camera) ScrollCallback(xoff float64, yoff float64) {
+	if c.Projection == Orthographic {
+		// zoom by showing less of the world, a tenth per step
+		c.OrthoHeight *= math.Pow(0.9, yoff)
+		return
+	}
+
+	c.Fov -= yoff
+	if c.Fov < 1.0 {
+		c.Fov = 1.0
+	}
+	if c.Fov > c.MaxFov {
+		c.Fov = c.MaxFov
+	}
+}
@@ -0,0 +1,263 @@
+package camera
+
+import (
+	"math"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+const epsilon = 1e-4
+
+// project transforms p by m, dividing by w.
+func project(m mgl.Mat4, p mgl.Vec3) mgl.Vec3 {
+	return mgl.TransformCoordinate(p, m)
+}
+
+func checkVec(t *testing.T, what string, got, want mgl.Vec3) {
+	t.Helper()
+	// mgl compares relative to the values, which fails for anything next to 0
+	if got.Sub(want).Len() > epsilon {
+		t.Errorf("%s is %v, want %v", what, got, want)
+	}
+}
+
+// testCamera returns a camera at 1,2,3 looking along -Z.
+func testCamera() Camera {
+	c := New()
+	c.CameraPos = mgl.Vec3{1, 2, 3}
+	c.SetFront(mgl.Vec3{0, 0, -1})
+	c.Near, c.Far, c.Aspect = 1, 10, 2
+	return c
+}
+
+func TestViewMatrix(t *testing.T) {
+	c := testCamera()
+	view := c.ViewMatrix()
+
+	// the camera sits at the origin looking down -Z with +Y up
+	checkVec(t, "camera position", project(view, c.CameraPos), mgl.Vec3{})
+	checkVec(t, "point in front", project(view, mgl.Vec3{1, 2, -7}), mgl.Vec3{0, 0, -10})
+	checkVec(t, "point above", project(view, mgl.Vec3{1, 3, 3}), mgl.Vec3{0, 1, 0})
+	checkVec(t, "point to the right", project(view, mgl.Vec3{2, 2, 3}), mgl.Vec3{1, 0, 0})
+}
+
+func TestProjectionMatrixPerspective(t *testing.T) {
+	c := testCamera()
+	c.Fov = 90
+	projection := c.ProjectionMatrix()
+
+	checkVec(t, "near plane", project(projection, mgl.Vec3{0, 0, -1}), mgl.Vec3{0, 0, -1})
+	checkVec(t, "far plane", project(projection, mgl.Vec3{0, 0, -10}), mgl.Vec3{0, 0, 1})
+	// 90 degrees up to down is 1 up per unit away, twice that to the side
+	checkVec(t, "top right near", project(projection, mgl.Vec3{2, 1, -1}), mgl.Vec3{1, 1, -1})
+	topRight := project(projection, mgl.Vec3{8, 4, -4})
+	checkVec(t, "top right further", mgl.Vec3{topRight.X(), topRight.Y(), 0}, mgl.Vec3{1, 1, 0})
+}
+
+func TestProjectionMatrixOrthographic(t *testing.T) {
+	c := testCamera()
+	c.Projection = Orthographic
+	c.OrthoHeight = 4
+	projection := c.ProjectionMatrix()
+
+	checkVec(t, "near plane", project(projection, mgl.Vec3{0, 0, -1}), mgl.Vec3{0, 0, -1})
+	checkVec(t, "far plane", project(projection, mgl.Vec3{0, 0, -10}), mgl.Vec3{0, 0, 1})
+	// OrthoHeight units from bottom to top at any distance, Aspect times as many from left to right
+	for _, z := range []float32{-1, -5, -10} {
+		corner := project(projection, mgl.Vec3{4, 2, z})
+		checkVec(t, "top right", mgl.Vec3{corner.X(), corner.Y(), 0}, mgl.Vec3{1, 1, 0})
+	}
+	// the field of view doesn't matter
+	c.Fov = 10
+	if !c.ProjectionMatrix().ApproxEqualThreshold(projection, epsilon) {
+		t.Error("the field of view changes the orthographic projection")
+	}
+}
+
+func TestViewProjection(t *testing.T) {
+	for _, projection := range []Projection{Perspective, Orthographic} {
+		c := testCamera()
+		c.Projection = projection
+		c.Rotate(30, 20)
+
+		viewProjection := c.ViewProjection()
+		if want := c.ProjectionMatrix().Mul4(c.ViewMatrix()); !viewProjection.ApproxEqualThreshold(want, epsilon) {
+			t.Errorf("projection %d: got %v, want %v", projection, viewProjection, want)
+		}
+		// whatever the camera looks at ends up in the middle of the screen
+		center := project(viewProjection, c.CameraPos.Add(c.CameraFront.Mul(5)))
+		checkVec(t, "point in front", mgl.Vec3{center.X(), center.Y(), 0}, mgl.Vec3{})
+	}
+}
+
+func TestScrollCallback(t *testing.T) {
+	c := New()
+	c.Fov, c.MaxFov = 45, 45
+	c.ScrollCallback(0, 5)
+	if c.Fov != 40 {
+		t.Errorf("field of view %g after zooming in 5 steps, want 40", c.Fov)
+	}
+	c.ScrollCallback(0, -100)
+	if c.Fov != c.MaxFov {
+		t.Errorf("field of view %g after zooming out, want at most %g", c.Fov, c.MaxFov)
+	}
+	c.ScrollCallback(0, 100)
+	if c.Fov != 1 {
+		t.Errorf("field of view %g after zooming in, want at least 1", c.Fov)
+	}
+
+	// orthographic zooms by showing less of the world, leaving the field of view alone
+	c.Projection = Orthographic
+	c.OrthoHeight = 10
+	c.ScrollCallback(0, 1)
+	if c.OrthoHeight >= 10 || c.Fov != 1 {
+		t.Errorf("height %g and field of view %g after zooming in, want less than 10 and 1", c.OrthoHeight, c.Fov)
+	}
+}
+
+func TestFirstInputKeepsDirection(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		setup func(c *Camera)
+		front mgl.Vec3
+	}{
+		{"new", func(c *Camera) {}, mgl.Vec3{-0.85, -0.36, -0.36}},
+		{"look at", func(c *Camera) {
+			c.CameraPos = mgl.Vec3{1, 2, 3}
+			c.LookAt(mgl.Vec3{4, 0, -3})
+		}, mgl.Vec3{3, -2, -6}},
+		{"roll", func(c *Camera) {
+			c.SetAngles(c.Yaw, c.Pitch, 30)
+			c.SetFront(mgl.Vec3{1, 0.5, 2})
+		}, mgl.Vec3{1, 0.5, 2}},
+		{"Z up", func(c *Camera) {
+			c.SetWorldUp(mgl.Vec3{0, 0, 1})
+			c.SetFront(mgl.Vec3{0.2, -1, 0.5})
+		}, mgl.Vec3{0.2, -1, 0.5}},
+		{"X down", func(c *Camera) {
+			c.SetWorldUp(mgl.Vec3{-2, 0, 0})
+			c.SetFront(mgl.Vec3{0.3, 0.4, -1})
+		}, mgl.Vec3{0.3, 0.4, -1}},
+		// steeper than the 89 degrees the mouse stops at
+		{"nearly up", func(c *Camera) { c.SetFront(mgl.Vec3{0.001, 1, 0.001}) }, mgl.Vec3{0.001, 1, 0.001}},
+		{"nearly down", func(c *Camera) {
+			c.SetWorldUp(mgl.Vec3{0, 0, 1})
+			c.SetFront(mgl.Vec3{0, -0.002, -1})
+		}, mgl.Vec3{0, -0.002, -1}},
+	} {
+		c := New()
+		test.setup(&c)
+		want := test.front.Normalize()
+		checkVec(t, test.name+": front", c.CameraFront, want)
+		up := c.CameraUp
+
+		// the first mouse move only remembers where the cursor is
+		c.FirstMouse = true
+		c.MouseCallback(640, 360)
+		checkVec(t, test.name+": front after the first mouse move", c.CameraFront, want)
+		checkVec(t, test.name+": up after the first mouse move", c.CameraUp, up)
+		c.MouseCallback(640, 360)
+		checkVec(t, test.name+": front after the mouse stood still", c.CameraFront, want)
+
+		c.Rotate(0, 0)
+		checkVec(t, test.name+": front after turning by nothing", c.CameraFront, want)
+		checkVec(t, test.name+": up after turning by nothing", c.CameraUp, up)
+	}
+}
+
+func TestMouseTurns(t *testing.T) {
+	c := New()
+	c.SetFront(mgl.Vec3{0, 0, -1})
+	c.Sensitivity = 0.5
+	c.FirstMouse = true
+	c.MouseCallback(100, 100)
+
+	// right and up on the screen turn right and up
+	c.MouseCallback(120, 80)
+	if c.Yaw != -90+10 || c.Pitch != 10 {
+		t.Errorf("yaw %g and pitch %g, want -80 and 10", c.Yaw, c.Pitch)
+	}
+	checkVec(t, "front", c.CameraFront, c.Direction(-80, 10))
+	if c.CameraFront.X() <= 0 || c.CameraFront.Y() <= 0 {
+		t.Errorf("front %v doesn't turn right and up", c.CameraFront)
+	}
+
+	// the mouse stops at 89 degrees
+	c.MouseCallback(120, -1000)
+	if c.Pitch != 89 {
+		t.Errorf("pitch %g, want 89", c.Pitch)
+	}
+}
+
+func TestSteepPitch(t *testing.T) {
+	c := New()
+	c.SetAngles(0, 89.5, 0)
+
+	// turning further up doesn't move, turning down does
+	c.Rotate(0, 5)
+	if c.Pitch != 89.5 {
+		t.Errorf("pitch %g after turning up, want 89.5", c.Pitch)
+	}
+	c.Rotate(0, -10)
+	if c.Pitch != 79.5 {
+		t.Errorf("pitch %g after turning down, want 79.5", c.Pitch)
+	}
+
+	// straight up still has a sideways direction to turn around
+	c.SetAngles(30, 90, 0)
+	checkVec(t, "front straight up", c.CameraFront, mgl.Vec3{0, 1, 0})
+	if length := c.CameraUp.Len(); length < 0.99 || length > 1.01 || c.CameraUp.Dot(c.CameraFront) > epsilon {
+		t.Errorf("up %v straight up isn't a unit vector across the front", c.CameraUp)
+	}
+}
+
+func TestRoll(t *testing.T) {
+	c := New()
+	c.SetAngles(0, 0, 0)
+	checkVec(t, "up without roll", c.CameraUp, mgl.Vec3{0, 1, 0})
+
+	// looking along +X, rolling clockwise tips the top of the view to the right, +Z
+	c.SetAngles(0, 0, 90)
+	checkVec(t, "front", c.CameraFront, mgl.Vec3{1, 0, 0})
+	checkVec(t, "up rolled 90 degrees", c.CameraUp, mgl.Vec3{0, 0, 1})
+
+	// turning keeps the roll
+	c.SetAngles(0, 20, 30)
+	c.Rotate(15, 10)
+	if c.Roll != 30 {
+		t.Errorf("roll %g after turning, want 30", c.Roll)
+	}
+	unrolled := New()
+	unrolled.SetAngles(c.Yaw, c.Pitch, 0)
+	right := c.CameraFront.Cross(unrolled.CameraUp)
+	checkVec(t, "rolled up", c.CameraUp, unrolled.CameraUp.Mul(0.8660254).Add(right.Mul(0.5)))
+}
+
+func TestWorldUp(t *testing.T) {
+	c := New()
+	c.SetWorldUp(mgl.Vec3{0, 0, 2})
+	if c.WorldUp != (mgl.Vec3{0, 0, 1}) {
+		t.Errorf("world up %v, want it normalized", c.WorldUp)
+	}
+
+	// pitch looks towards the up of the world, and yaw turns around it
+	checkVec(t, "straight up", c.Direction(45, 90), mgl.Vec3{0, 0, 1})
+	for _, yaw := range []float64{0, 90, 180} {
+		if direction := c.Direction(yaw, 0); direction.Z() > epsilon || direction.Z() < -epsilon {
+			t.Errorf("yaw %g points out of the horizon, along %v", yaw, direction)
+		}
+	}
+	for _, angles := range [][2]float64{{0, 0}, {30, 20}, {-120, -60}, {170, 85}} {
+		yaw, pitch := c.Angles(c.Direction(angles[0], angles[1]))
+		if math.Abs(yaw-angles[0]) > epsilon || math.Abs(pitch-angles[1]) > epsilon {
+			t.Errorf("angles %v come back as %g, %g", angles, yaw, pitch)
+		}
+	}
+
+	// the view keeps the world up towards the top of the screen
+	c.SetFront(mgl.Vec3{1, 1, 0})
+	if c.CameraUp.Z() <= 0 {
+		t.Errorf("up %v points down with Z up", c.CameraUp)
+	}
+}
@@ -1,38 +1,244 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strconv"
+	"strings"
 )
 
 type Config struct {
-	Width  int `json:"width"`
-	Height int `json:"height"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Title  string `json:"title"`
+	Scene  string `json:"scene"`
+	// AssetRoots are the directories assets are looked up in, in order.
+	AssetRoots []string `json:"assetRoots"`
+	// Skybox picks one of the skyboxes of the scene instead of the one the scene selects, "none" draws none.
+	Skybox string `json:"skybox,omitempty"`
+
+	// VSync waits for the vertical blank before showing a frame.
+	VSync     bool `json:"vsync"`
+	Resizable bool `json:"resizable"`
+	// WindowMode is how the window starts out, F11 switches between windowed and fullscreen.
+	WindowMode WindowMode `json:"windowMode"`
+	// Monitor is the index or name of the monitor to go fullscreen on, the primary monitor if it's empty.
+	Monitor string `json:"monitor"`
+	// Samples is the number of samples per pixel for multisample anti-aliasing, 0 turns it off.
+	Samples int `json:"samples"`
+
+	// Projection is how the camera starts out projecting the scene, P switches between them.
+	Projection Projection `json:"projection"`
+	// FOV is the vertical field of view in degrees, Near and Far are the distances of the clipping planes.
+	FOV  float64 `json:"fov"`
+	Near float64 `json:"near"`
+	Far  float64 `json:"far"`
+	// Sensitivity is how many degrees the camera turns per pixel the mouse moves.
+	Sensitivity float64 `json:"sensitivity"`
+	// Up is the up direction of the world, the camera turns around it.
+	Up [3]float32 `json:"up"`
+	// ClearColor is the background colour, red, green and blue from 0 to 1.
+	ClearColor [3]float32 `json:"clearColor"`
 }
 
-func ReadFile(cfgFile string) *Config {
-	cnf := Config{}
+// WindowMode is how the window is shown.
+type WindowMode string
 
-	jsonFile, err := os.Open(cfgFile)
-	if err != nil {
-		fmt.Println(err)
+const (
+	// Windowed is a normal window with decorations.
+	Windowed WindowMode = "windowed"
+	// Borderless covers the whole monitor without changing its video mode.
+	Borderless WindowMode = "borderless"
+	// Fullscreen takes over the monitor exclusively, switching it to the resolution closest to the window size.
+	Fullscreen WindowMode = "fullscreen"
+)
+
+// Projection is how the camera projects the scene onto the window.
+type Projection string
+
+const (
+	// Perspective makes things smaller the further away they are.
+	Perspective Projection = "perspective"
+	// Orthographic keeps sizes regardless of the distance, like a technical drawing.
+	Orthographic Projection = "orthographic"
+)
+
+// Default returns the configuration used for everything the config file leaves out.
+func Default() *Config {
+	return &Config{
+		Width:       1280,
+		Height:      720,
+		Title:       "Learn OpenGL",
+		Scene:       "scenes/default.json",
+		AssetRoots:  []string{"resources"},
+		VSync:       true,
+		Resizable:   true,
+		WindowMode:  Windowed,
+		Projection:  Perspective,
+		FOV:         45,
+		Near:        0.1,
+		Far:         100,
+		Sensitivity: 0.2,
+		Up:          [3]float32{0, 1, 0},
+		ClearColor:  [3]float32{0.126, 0.145, 0.2},
 	}
+}
+
+// ReadFile reads the config file cfgFile on top of the defaults. It doesn't validate the result.
+func ReadFile(cfgFile string) (*Config, error) {
+	cnf := Default()
 
-	fileContents, err := ioutil.ReadAll(jsonFile)
+	fileContents, err := ioutil.ReadFile(cfgFile)
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
+	decoder := json.NewDecoder(bytes.NewReader(fileContents))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cnf); err != nil {
+		return nil, fmt.Errorf("config %q: %w", cfgFile, err)
+	}
+	return cnf, nil
+}
 
-	err = json.Unmarshal(fileContents, &cnf)
+// Load reads the config file named by the -config flag in args, default.json if there is none, overrides its
+// values with the other flags in args and validates the result. Without the flag a missing default.json isn't
+// an error, the defaults are used instead. Run the application with -help for the flags.
+func Load(args []string) (*Config, error) {
+	// parse into a throwaway config first, to find the config file and which flags are set
+	flags := flagSet(Default())
+	cfgFile := flags.String("config", "default.json", "config `file` to read")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
 
+	explicit := false
+	flags.Visit(func(f *flag.Flag) {
+		explicit = explicit || f.Name == "config"
+	})
+
+	cnf, err := ReadFile(*cfgFile)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		cnf, err = Default(), nil
+	}
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
-	fmt.Println("Successfully Opened ", cfgFile)
 
-	defer jsonFile.Close()
+	overrides := flagSet(cnf)
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name != "config" && err == nil {
+			err = overrides.Set(f.Name, f.Value.String())
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return &cnf
+	if err := cnf.Validate(); err != nil {
+		return nil, fmt.Errorf("config %q: %w", *cfgFile, err)
+	}
+	return cnf, nil
+}
+
+// flagSet returns the command line flags that set the fields of cnf.
+func flagSet(cnf *Config) *flag.FlagSet {
+	flags := flag.NewFlagSet("learnopengl", flag.ContinueOnError)
+	flags.IntVar(&cnf.Width, "width", cnf.Width, "window width")
+	flags.IntVar(&cnf.Height, "height", cnf.Height, "window height")
+	flags.StringVar(&cnf.Title, "title", cnf.Title, "window title")
+	flags.StringVar(&cnf.Scene, "scene", cnf.Scene, "scene asset to load")
+	flags.Var((*listValue)(&cnf.AssetRoots), "asset-roots", "comma separated `directories` to look for assets in, in order")
+	flags.StringVar(&cnf.Skybox, "skybox", cnf.Skybox, "skybox of the scene to draw, or none")
+	flags.BoolVar(&cnf.VSync, "vsync", cnf.VSync, "wait for the vertical blank")
+	flags.BoolVar(&cnf.Resizable, "resizable", cnf.Resizable, "let the window be resized")
+	flags.StringVar((*string)(&cnf.WindowMode), "window-mode", string(cnf.WindowMode), "windowed, borderless or fullscreen")
+	flags.StringVar(&cnf.Monitor, "monitor", cnf.Monitor, "index or name of the monitor to go fullscreen on")
+	flags.IntVar(&cnf.Samples, "samples", cnf.Samples, "samples per pixel for anti-aliasing, 0 for none")
+	flags.StringVar((*string)(&cnf.Projection), "projection", string(cnf.Projection), "perspective or orthographic")
+	flags.Float64Var(&cnf.FOV, "fov", cnf.FOV, "vertical field of view in degrees")
+	flags.Float64Var(&cnf.Near, "near", cnf.Near, "distance of the near clipping plane")
+	flags.Float64Var(&cnf.Far, "far", cnf.Far, "distance of the far clipping plane")
+	flags.Float64Var(&cnf.Sensitivity, "sensitivity", cnf.Sensitivity, "mouse sensitivity in degrees per pixel")
+	flags.Var((*vec3Value)(&cnf.Up), "up", "up direction of the world as `x,y,z`")
+	flags.Var((*vec3Value)(&cnf.ClearColor), "clear-color", "background colour as `r,g,b` from 0 to 1")
+	return flags
+}
+
+// Validate checks that the values make sense, so a typo in the config doesn't show up as a black window.
+func (c *Config) Validate() error {
+	switch {
+	case c.Width <= 0 || c.Height <= 0:
+		return fmt.Errorf("window size %dx%d is not positive", c.Width, c.Height)
+	case c.Scene == "":
+		return fmt.Errorf("no scene")
+	case c.WindowMode != Windowed && c.WindowMode != Borderless && c.WindowMode != Fullscreen:
+		return fmt.Errorf("window mode %q is not windowed, borderless or fullscreen", c.WindowMode)
+	case c.Samples < 0:
+		return fmt.Errorf("samples %d is negative", c.Samples)
+	case c.Projection != Perspective && c.Projection != Orthographic:
+		return fmt.Errorf("projection %q is not perspective or orthographic", c.Projection)
+	case c.FOV <= 0 || c.FOV >= 180:
+		return fmt.Errorf("field of view %g is not between 0 and 180 degrees", c.FOV)
+	case c.Near <= 0:
+		return fmt.Errorf("near plane %g is not in front of the camera", c.Near)
+	case c.Far <= c.Near:
+		return fmt.Errorf("far plane %g is not beyond the near plane %g", c.Far, c.Near)
+	case c.Sensitivity <= 0:
+		return fmt.Errorf("sensitivity %g is not positive", c.Sensitivity)
+	case c.Up == [3]float32{}:
+		return fmt.Errorf("the up direction is zero")
+	}
+	for _, component := range c.ClearColor {
+		if component < 0 || component > 1 {
+			return fmt.Errorf("clear colour %v is not between 0 and 1", c.ClearColor)
+		}
+	}
+	return nil
+}
+
+// vec3Value is a flag holding a vector or an RGB colour, written as three comma separated numbers.
+type vec3Value [3]float32
+
+func (v *vec3Value) String() string {
+	return fmt.Sprintf("%g,%g,%g", v[0], v[1], v[2])
+}
+
+func (v *vec3Value) Set(s string) error {
+	components := strings.Split(s, ",")
+	if len(components) != 3 {
+		return fmt.Errorf("want three comma separated numbers")
+	}
+	var vector vec3Value
+	for i, component := range components {
+		value, err := strconv.ParseFloat(strings.TrimSpace(component), 32)
+		if err != nil {
+			return err
+		}
+		vector[i] = float32(value)
+	}
+	*v = vector
+	return nil
+}
+
+// listValue is a flag holding a comma separated list of strings.
+type listValue []string
+
+func (v *listValue) String() string {
+	return strings.Join(*v, ",")
+}
+
+func (v *listValue) Set(s string) error {
+	var list listValue
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*v = list
+	return nil
 }
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// inTempDir runs the test in an empty directory, so no default.json is found.
+func inTempDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+func TestLoadWithoutDefaultFile(t *testing.T) {
+	inTempDir(t)
+
+	cnf, err := Load([]string{"-width", "800", "-clear-color", "0,0,1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Default()
+	want.Width = 800
+	want.ClearColor = [3]float32{0, 0, 1}
+	if cnf.Width != want.Width || cnf.Height != want.Height || cnf.ClearColor != want.ClearColor || cnf.Scene != want.Scene {
+		t.Errorf("got %+v, want %+v", cnf, want)
+	}
+}
+
+func TestLoadMissingExplicitFile(t *testing.T) {
+	inTempDir(t)
+
+	for _, name := range []string{"other.json", "default.json"} {
+		if _, err := Load([]string{"-config", name}); err == nil {
+			t.Errorf("no error for the missing %s", name)
+		}
+	}
+}
+
+func TestLoadFileAndFlags(t *testing.T) {
+	dir := inTempDir(t)
+	if err := ioutil.WriteFile(filepath.Join(dir, "default.json"), []byte(`{"width": 640, "height": 480, "fov": 60}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cnf, err := Load([]string{"-height", "400", "-asset-roots", "mods, resources", "-up", "0,0,1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the file overrides the defaults and the flags override the file
+	if cnf.Width != 640 || cnf.Height != 400 || cnf.FOV != 60 || cnf.Title != Default().Title {
+		t.Errorf("got %+v", cnf)
+	}
+	if len(cnf.AssetRoots) != 2 || cnf.AssetRoots[0] != "mods" || cnf.AssetRoots[1] != "resources" || cnf.Up != [3]float32{0, 0, 1} {
+		t.Errorf("got asset roots %q and up %v", cnf.AssetRoots, cnf.Up)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := inTempDir(t)
+	for name, contents := range map[string]string{
+		"broken.json": `{"width": "wide"}`,
+		"typo.json":   `{"widht": 800}`,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"-config", "broken.json"},
+		{"-config", "typo.json"},
+		{"-width", "wide"},
+		{"-width", "0"},
+		{"-near", "10", "-far", "1"},
+		{"-window-mode", "maximized"},
+		{"-projection", "isometric"},
+		{"-clear-color", "1,1"},
+		{"-clear-color", "2,0,0"},
+		{"-up", "0,0,0"},
+		{"-up", "0,1"},
+		{"-no-such-flag"},
+	} {
+		if _, err := Load(args); err == nil {
+			t.Errorf("%q: no error", args)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Package glsl prepares GLSL source code before it is handed to the driver: it resolves #include directives
+// and injects #defines, while keeping track of where every line came from so error messages from the
+// driver can point at the original file.
+package glsl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Preprocessor resolves #include "file" directives and adds #defines to GLSL sources.
+//
+// File names use forward slashes. Included files are looked up relative to Root. A file is included
+// at most once per source, so several files can include the same definitions; including a file that
+// is still being processed is an include cycle and an error.
+type Preprocessor struct {
+	// Root is the directory #include paths are relative to, e.g. "shaders".
+	Root string
+	// Defines are added as "#define name value" right after the #version line of every source.
+	Defines map[string]string
+	// ReadFile reads a file. It defaults to reading from disk relative to the working directory.
+	ReadFile func(name string) ([]byte, error)
+}
+
+// Location is a line in one of the files that make up a processed source.
+type Location struct {
+	File string
+	Line int
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d", l.File, l.Line)
+}
+
+// Source is the result of preprocessing a file.
+type Source struct {
+	// Code is the GLSL code with the includes resolved and the defines added.
+	Code string
+	// Files are the names of every file read to produce Code, starting with the file itself.
+	Files []string
+
+	lines []Location
+}
+
+// Location returns where line (counting from 1) of Code came from.
+func (s *Source) Location(line int) Location {
+	if line < 1 || line > len(s.lines) {
+		return Location{File: s.Files[0], Line: line}
+	}
+	return s.lines[line-1]
+}
+
+// Error is a problem found while preprocessing, at the line that caused it.
+type Error struct {
+	Location
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Location, e.Msg)
+}
+
+// definesFile is the file name the injected defines are attributed to.
+const definesFile = "<defines>"
+
+var (
+	includeDirective = regexp.MustCompile(`^\s*#\s*include\s+"([^"]+)"\s*(//.*)?$`)
+	versionDirective = regexp.MustCompile(`^\s*#\s*version\b`)
+)
+
+// Process reads the file called name and resolves its includes.
+func (p *Preprocessor) Process(name string) (*Source, error) {
+	code, err := p.readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return p.ProcessString(name, string(code))
+}
+
+// ProcessString resolves the includes of code, which was read from the file called name.
+func (p *Preprocessor) ProcessString(name string, code string) (*Source, error) {
+	name = path.Clean(filepath.ToSlash(name))
+	state := &processState{
+		preprocessor: p,
+		source:       &Source{Files: []string{name}},
+		included:     map[string]bool{name: true},
+	}
+
+	if err := state.process(name, code, []string{name}, true); err != nil {
+		return nil, err
+	}
+
+	state.source.Code = state.code.String()
+	return state.source, nil
+}
+
+// processState collects the output of one call to Process.
+type processState struct {
+	preprocessor *Preprocessor
+	source       *Source
+	code         strings.Builder
+	included     map[string]bool
+}
+
+func (s *processState) emit(line string, location Location) {
+	s.code.WriteString(line)
+	s.code.WriteByte('\n')
+	s.source.lines = append(s.source.lines, location)
+}
+
+// process appends the lines of code to the output. stack holds the files being processed, to detect cycles.
+func (s *processState) process(name string, code string, stack []string, topLevel bool) error {
+	lines := strings.Split(strings.ReplaceAll(code, "\r\n", "\n"), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// without a #version line the defines go first, #version has to stay the very first line
+	if topLevel && !hasVersion(lines) {
+		s.emitDefines()
+	}
+
+	for i, line := range lines {
+		location := Location{File: name, Line: i + 1}
+
+		if versionDirective.MatchString(line) {
+			if !topLevel {
+				return &Error{Location: location, Msg: "#version is only allowed in the main file, not in an included file"}
+			}
+			s.emit(line, location)
+			s.emitDefines()
+			continue
+		}
+
+		match := includeDirective.FindStringSubmatch(line)
+		if match == nil {
+			s.emit(line, location)
+			continue
+		}
+
+		include := path.Join(s.preprocessor.Root, match[1])
+		for j, open := range stack {
+			if open == include {
+				cycle := append(append([]string{}, stack[j:]...), include)
+				return &Error{Location: location, Msg: "include cycle " + strings.Join(cycle, " -> ")}
+			}
+		}
+		if s.included[include] {
+			// already included, the definitions are there
+			continue
+		}
+		s.included[include] = true
+
+		includedCode, err := s.preprocessor.readFile(include)
+		if err != nil {
+			return &Error{Location: location, Msg: err.Error()}
+		}
+		s.source.Files = append(s.source.Files, include)
+
+		if err := s.process(include, string(includedCode), append(stack, include), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *processState) emitDefines() {
+	names := make([]string, 0, len(s.preprocessor.Defines))
+	for name := range s.preprocessor.Defines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		s.emit(fmt.Sprintf("#define %s %s", name, s.preprocessor.Defines[name]), Location{File: definesFile, Line: i + 1})
+	}
+}
+
+func hasVersion(lines []string) bool {
+	for _, line := range lines {
+		if versionDirective.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Preprocessor) readFile(name string) ([]byte, error) {
+	if p.ReadFile != nil {
+		return p.ReadFile(name)
+	}
+	return ioutil.ReadFile(filepath.FromSlash(name))
+}
@@ -0,0 +1,147 @@
+package glsl
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// files returns a preprocessor reading from an in-memory file system under the root "shaders".
+func files(sources map[string]string) *Preprocessor {
+	return &Preprocessor{
+		Root: "shaders",
+		ReadFile: func(name string) ([]byte, error) {
+			code, ok := sources[name]
+			if !ok {
+				return nil, os.ErrNotExist
+			}
+			return []byte(code), nil
+		},
+	}
+}
+
+func TestProcessIncludes(t *testing.T) {
+	p := files(map[string]string{
+		"shaders/main.frag":           "#version 330 core\n#include \"include/lights.glsl\"\n#include \"include/camera.glsl\"\nvoid main() {}\n",
+		"shaders/include/lights.glsl": "// lights\n#include \"include/common.glsl\"\nvec3 light;\n",
+		"shaders/include/camera.glsl": "#include \"include/common.glsl\" // again\nvec3 viewPos;",
+		"shaders/include/common.glsl": "#define PI 3.14159\n",
+		"shaders/include/unused.glsl": "#error not included\n",
+	})
+	p.Defines = map[string]string{"MAX_SPOT_LIGHTS": "4", "MAX_POINT_LIGHTS": "8"}
+
+	source, err := p.Process("shaders/main.frag")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the defines follow #version, sorted, and common.glsl is only included once
+	wantCode := strings.Join([]string{
+		"#version 330 core",
+		"#define MAX_POINT_LIGHTS 8",
+		"#define MAX_SPOT_LIGHTS 4",
+		"// lights",
+		"#define PI 3.14159",
+		"vec3 light;",
+		"vec3 viewPos;",
+		"void main() {}",
+	}, "\n") + "\n"
+	if source.Code != wantCode {
+		t.Errorf("got code\n%s\nwant\n%s", source.Code, wantCode)
+	}
+	wantFiles := []string{"shaders/main.frag", "shaders/include/lights.glsl", "shaders/include/common.glsl", "shaders/include/camera.glsl"}
+	if !reflect.DeepEqual(source.Files, wantFiles) {
+		t.Errorf("got files %q, want %q", source.Files, wantFiles)
+	}
+
+	for line, want := range map[int]Location{
+		1: {"shaders/main.frag", 1},
+		2: {"<defines>", 1},
+		3: {"<defines>", 2},
+		4: {"shaders/include/lights.glsl", 1},
+		5: {"shaders/include/common.glsl", 1},
+		6: {"shaders/include/lights.glsl", 3},
+		7: {"shaders/include/camera.glsl", 2},
+		8: {"shaders/main.frag", 4},
+		// past the end is taken to be in the main file
+		20: {"shaders/main.frag", 20},
+	} {
+		if got := source.Location(line); got != want {
+			t.Errorf("line %d comes from %v, want %v", line, got, want)
+		}
+	}
+}
+
+func TestProcessDefinesWithoutVersion(t *testing.T) {
+	p := files(nil)
+	p.Defines = map[string]string{"N": "2"}
+
+	source, err := p.ProcessString("shaders/part.glsl", "// no version\nfloat x[N];\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "#define N 2\n// no version\nfloat x[N];\n"; source.Code != want {
+		t.Errorf("got code %q, want %q", source.Code, want)
+	}
+	if got := source.Location(2); got != (Location{"shaders/part.glsl", 1}) {
+		t.Errorf("line 2 comes from %v", got)
+	}
+}
+
+func TestProcessErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources map[string]string
+		at      Location
+		msg     string
+	}{
+		{
+			name: "cycle",
+			sources: map[string]string{
+				"shaders/main.frag": "#version 330 core\n#include \"a.glsl\"\n",
+				"shaders/a.glsl":    "#include \"b.glsl\"\n",
+				"shaders/b.glsl":    "float b;\n#include \"a.glsl\"\n",
+			},
+			at:  Location{"shaders/b.glsl", 2},
+			msg: "include cycle shaders/a.glsl -> shaders/b.glsl -> shaders/a.glsl",
+		},
+		{
+			name: "includes itself",
+			sources: map[string]string{
+				"shaders/main.frag": "#include \"main.frag\"\n",
+			},
+			at:  Location{"shaders/main.frag", 1},
+			msg: "include cycle shaders/main.frag -> shaders/main.frag",
+		},
+		{
+			name: "missing",
+			sources: map[string]string{
+				"shaders/main.frag": "#version 330 core\n\n#include \"missing.glsl\"\n",
+			},
+			at:  Location{"shaders/main.frag", 3},
+			msg: os.ErrNotExist.Error(),
+		},
+		{
+			name: "version in an include",
+			sources: map[string]string{
+				"shaders/main.frag": "#version 330 core\n#include \"a.glsl\"\n",
+				"shaders/a.glsl":    "#version 330 core\n",
+			},
+			at:  Location{"shaders/a.glsl", 1},
+			msg: "#version is only allowed in the main file, not in an included file",
+		},
+	}
+	for _, test := range tests {
+		_, err := files(test.sources).Process("shaders/main.frag")
+		var processErr *Error
+		if !errors.As(err, &processErr) {
+			t.Errorf("%s: got error %v, want a *glsl.Error", test.name, err)
+			continue
+		}
+		if processErr.Location != test.at || processErr.Msg != test.msg {
+			t.Errorf("%s: got %v, want %v: %s", test.name, processErr, test.at, test.msg)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package graphics
+
+import (
+	"math"
+
+	"github.com/PetrusJPrinsloo/learnopengl/camera"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// CameraController turns mouse and keyboard input into camera movement. One controller drives the camera at
+// a time, and they can be swapped while the application runs. Controllers get the input as values, so they
+// work without a window; ReadInput and ActivateController connect them to one.
+type CameraController interface {
+	// CursorMode is the glfw cursor mode the controller wants while it drives the camera.
+	CursorMode() int
+	// Activate is called when the controller takes over the camera. It picks up the view the camera has.
+	Activate(c *camera.Camera, input Input)
+	// MouseMove is called whenever the cursor moves.
+	MouseMove(c *camera.Camera, input Input)
+	// Scroll is called whenever the mouse wheel turns.
+	Scroll(c *camera.Camera, xoff, yoff float64)
+	// Update is called every frame with the seconds since the previous one, for keys that are held down.
+	Update(c *camera.Camera, input Input, deltaTime float64)
+}
+
+// Input is the state of the mouse and keyboard the controllers react to.
+type Input struct {
+	// CursorX and CursorY are the position of the cursor in screen coordinates.
+	CursorX float64
+	CursorY float64
+	// Buttons and Keys hold the mouse buttons and keys that are pressed.
+	Buttons map[glfw.MouseButton]bool
+	Keys    map[glfw.Key]bool
+}
+
+// The buttons and keys ReadInput looks at, the ones the controllers use.
+var (
+	controllerButtons = []glfw.MouseButton{glfw.MouseButtonLeft, glfw.MouseButtonMiddle}
+	controllerKeys    = []glfw.Key{glfw.KeyW, glfw.KeyS, glfw.KeyA, glfw.KeyD, glfw.KeyQ, glfw.KeyE}
+)
+
+// ReadInput reads the cursor position and the buttons and keys the controllers use from window.
+func ReadInput(window *glfw.Window) Input {
+	input := Input{Buttons: map[glfw.MouseButton]bool{}, Keys: map[glfw.Key]bool{}}
+	input.CursorX, input.CursorY = window.GetCursorPos()
+	for _, button := range controllerButtons {
+		input.Buttons[button] = window.GetMouseButton(button) == glfw.Press
+	}
+	for _, key := range controllerKeys {
+		input.Keys[key] = window.GetKey(key) == glfw.Press
+	}
+	return input
+}
+
+// ActivateController sets the cursor mode controller wants on window and hands it the camera.
+func ActivateController(controller CameraController, c *camera.Camera, window *glfw.Window) {
+	window.SetInputMode(glfw.CursorMode, controller.CursorMode())
+	controller.Activate(c, ReadInput(window))
+}
+
+// FPSController looks around with the mouse, walks with WASD and rolls with Q and E, with the cursor hidden.
+type FPSController struct {
+	// Speed is how far the camera moves per second, RollSpeed how many degrees it rolls per second.
+	Speed     float64
+	RollSpeed float64
+}
+
+// NewFPSController returns a controller walking 2.5 units and rolling 45 degrees per second.
+func NewFPSController() *FPSController {
+	return &FPSController{Speed: 2.5, RollSpeed: 45}
+}
+
+func (f *FPSController) CursorMode() int {
+	return glfw.CursorDisabled
+}
+
+func (f *FPSController) Activate(c *camera.Camera, input Input) {
+	// the cursor jumps when it gets hidden, don't turn the camera for it
+	c.FirstMouse = true
+}
+
+func (f *FPSController) MouseMove(c *camera.Camera, input Input) {
+	c.MouseCallback(input.CursorX, input.CursorY)
+}
+
+func (f *FPSController) Scroll(c *camera.Camera, xoff, yoff float64) {
+	c.ScrollCallback(xoff, yoff)
+}
+
+func (f *FPSController) Update(c *camera.Camera, input Input, deltaTime float64) {
+	cameraSpeed := float32(f.Speed * deltaTime)
+	right := c.CameraFront.Cross(c.CameraUp).Normalize()
+
+	if input.Keys[glfw.KeyW] {
+		c.CameraPos = c.CameraPos.Add(c.CameraFront.Mul(cameraSpeed))
+	}
+	if input.Keys[glfw.KeyS] {
+		c.CameraPos = c.CameraPos.Sub(c.CameraFront.Mul(cameraSpeed))
+	}
+	if input.Keys[glfw.KeyA] {
+		c.CameraPos = c.CameraPos.Sub(right.Mul(cameraSpeed))
+	}
+	if input.Keys[glfw.KeyD] {
+		c.CameraPos = c.CameraPos.Add(right.Mul(cameraSpeed))
+	}
+
+	if input.Keys[glfw.KeyQ] {
+		c.SetAngles(c.Yaw, c.Pitch, c.Roll-f.RollSpeed*deltaTime)
+	}
+	if input.Keys[glfw.KeyE] {
+		c.SetAngles(c.Yaw, c.Pitch, c.Roll+f.RollSpeed*deltaTime)
+	}
+}
+
+// OrbitController circles the camera around a target point, for inspecting models. Dragging with the left
+// button rotates around the target, dragging with the middle button pans it and scrolling dollies towards it.
+type OrbitController struct {
+	Target   mgl.Vec3
+	Distance float64
+	// Yaw and Pitch are the angles in degrees of the direction from the target to the camera.
+	Yaw   float64
+	Pitch float64
+
+	// RotateSpeed is in degrees per pixel, PanSpeed in distances to the target per pixel and DollySpeed
+	// the fraction of the distance a scroll step moves.
+	RotateSpeed float64
+	PanSpeed    float64
+	DollySpeed  float64
+	MinDistance float64
+	MaxDistance float64
+
+	lastX float64
+	lastY float64
+}
+
+// NewOrbitController returns a controller that starts orbiting 5 units in front of the camera.
+func NewOrbitController() *OrbitController {
+	return &OrbitController{
+		Distance:    5,
+		RotateSpeed: 0.3,
+		PanSpeed:    0.002,
+		DollySpeed:  0.1,
+		MinDistance: 0.1,
+		MaxDistance: 1000,
+	}
+}
+
+func (o *OrbitController) CursorMode() int {
+	return glfw.CursorNormal
+}
+
+// Activate orbits around the point Distance in front of the camera, so the view doesn't move.
+func (o *OrbitController) Activate(c *camera.Camera, input Input) {
+	o.lastX, o.lastY = input.CursorX, input.CursorY
+
+	front := c.CameraFront.Normalize()
+	o.Target = c.CameraPos.Add(front.Mul(float32(o.Distance)))
+	o.Yaw, o.Pitch = c.Angles(front.Mul(-1))
+	o.apply(c)
+}
+
+func (o *OrbitController) MouseMove(c *camera.Camera, input Input) {
+	dx, dy := input.CursorX-o.lastX, input.CursorY-o.lastY
+	o.lastX, o.lastY = input.CursorX, input.CursorY
+
+	switch {
+	case input.Buttons[glfw.MouseButtonLeft]:
+		o.Yaw += dx * o.RotateSpeed
+		// stop at 89 degrees like the camera does, without snapping back from a steeper start
+		limit := math.Max(89, math.Abs(o.Pitch))
+		o.Pitch = math.Max(-limit, math.Min(limit, o.Pitch+dy*o.RotateSpeed))
+	case input.Buttons[glfw.MouseButtonMiddle]:
+		// move the target with the cursor, in the plane facing the camera
+		right := c.CameraFront.Cross(c.CameraUp).Normalize()
+		up := right.Cross(c.CameraFront).Normalize()
+		scale := float32(o.Distance * o.PanSpeed)
+		o.Target = o.Target.Sub(right.Mul(float32(dx) * scale)).Add(up.Mul(float32(dy) * scale))
+	default:
+		return
+	}
+	o.apply(c)
+}
+
+func (o *OrbitController) Scroll(c *camera.Camera, xoff, yoff float64) {
+	o.Distance *= math.Pow(1-o.DollySpeed, yoff)
+	o.Distance = math.Max(o.MinDistance, math.Min(o.MaxDistance, o.Distance))
+	if c.Projection == camera.Orthographic {
+		// moving closer doesn't make anything bigger without perspective, zoom instead
+		c.ScrollCallback(xoff, yoff)
+	}
+	o.apply(c)
+}
+
+func (o *OrbitController) Update(c *camera.Camera, input Input, deltaTime float64) {
+}
+
+// FrameSelected orbits around the centre of a bounding sphere and moves the camera back until all of it is in view.
+func (o *OrbitController) FrameSelected(c *camera.Camera, center mgl.Vec3, radius float64) {
+	o.Target = center
+	halfFov := float64(mgl.DegToRad(float32(c.Fov))) / 2
+	if c.Aspect < 1 {
+		// the horizontal field of view is the narrower one
+		halfFov = math.Atan(math.Tan(halfFov) * c.Aspect)
+	}
+	o.Distance = math.Max(o.MinDistance, radius/math.Sin(halfFov))
+	if c.Projection == camera.Orthographic {
+		c.OrthoHeight = 2 * radius * math.Max(1, 1/c.Aspect)
+	}
+	o.apply(c)
+}
+
+// apply places the camera on its orbit, looking at the target. The angles turn around the up direction of the camera.
+func (o *OrbitController) apply(c *camera.Camera) {
+	c.CameraPos = o.Target.Add(c.Direction(o.Yaw, o.Pitch).Mul(float32(o.Distance)))
+	c.LookAt(o.Target)
+}
@@ -0,0 +1,152 @@
+package graphics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/PetrusJPrinsloo/learnopengl/camera"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+func TestOrbitActivateKeepsView(t *testing.T) {
+	for _, up := range []mgl.Vec3{{0, 1, 0}, {0, 0, 1}} {
+		c := camera.New()
+		c.SetWorldUp(up)
+		position, front := c.CameraPos, c.CameraFront
+
+		o := NewOrbitController()
+		o.Activate(&c, Input{CursorX: 100, CursorY: 50})
+		if c.CameraPos.Sub(position).Len() > 1e-4 || c.CameraFront.Sub(front).Len() > 1e-4 {
+			t.Errorf("up %v: the camera moved from %v looking along %v to %v looking along %v", up, position, front, c.CameraPos, c.CameraFront)
+		}
+		if target := position.Add(front.Mul(float32(o.Distance))); o.Target.Sub(target).Len() > 1e-4 {
+			t.Errorf("up %v: orbiting around %v, want %v", up, o.Target, target)
+		}
+
+		// the cursor position is picked up, so the first drag doesn't jump
+		o.MouseMove(&c, Input{CursorX: 100, CursorY: 50, Buttons: map[glfw.MouseButton]bool{glfw.MouseButtonLeft: true}})
+		if c.CameraPos.Sub(position).Len() > 1e-4 {
+			t.Errorf("up %v: the camera moved to %v without the cursor moving", up, c.CameraPos)
+		}
+	}
+}
+
+func TestOrbitMouseMove(t *testing.T) {
+	c := camera.New()
+	o := NewOrbitController()
+	o.Activate(&c, Input{})
+	yaw, pitch := o.Yaw, o.Pitch
+
+	// without a button pressed the cursor just moves
+	o.MouseMove(&c, Input{CursorX: 10, CursorY: 10})
+	if o.Yaw != yaw || o.Pitch != pitch {
+		t.Errorf("turned to %g, %g without a button", o.Yaw, o.Pitch)
+	}
+
+	left := map[glfw.MouseButton]bool{glfw.MouseButtonLeft: true}
+	o.MouseMove(&c, Input{CursorX: 20, CursorY: 10, Buttons: left})
+	if want := yaw + 10*o.RotateSpeed; math.Abs(o.Yaw-want) > 1e-9 || o.Pitch != pitch {
+		t.Errorf("turned to %g, %g, want %g, %g", o.Yaw, o.Pitch, want, pitch)
+	}
+	o.MouseMove(&c, Input{CursorX: 20, CursorY: 10000, Buttons: left})
+	if o.Pitch != 89 {
+		t.Errorf("pitch %g, want it to stop at 89", o.Pitch)
+	}
+	if distance := c.CameraPos.Sub(o.Target).Len(); math.Abs(float64(distance)-o.Distance) > 1e-4 {
+		t.Errorf("the camera is %g from the target, want %g", distance, o.Distance)
+	}
+
+	// panning moves the target and the camera along with it
+	target, position := o.Target, c.CameraPos
+	o.MouseMove(&c, Input{CursorX: 120, CursorY: 10000, Buttons: map[glfw.MouseButton]bool{glfw.MouseButtonMiddle: true}})
+	moved := o.Target.Sub(target)
+	if want := float32(100 * o.Distance * o.PanSpeed); math.Abs(float64(moved.Len()-want)) > 1e-4 {
+		t.Errorf("the target moved %g, want %g", moved.Len(), want)
+	}
+	if c.CameraPos.Sub(position).Sub(moved).Len() > 1e-4 {
+		t.Errorf("the camera moved %v, the target %v", c.CameraPos.Sub(position), moved)
+	}
+}
+
+func TestOrbitFrameSelected(t *testing.T) {
+	center := mgl.Vec3{1, 2, 3}
+	tests := []struct {
+		name        string
+		aspect      float64
+		radius      float64
+		distance    float64
+		orthoHeight float64
+	}{
+		// wider than high, the vertical field of view of 45 degrees decides
+		{"wide", 16.0 / 9, 2, 5.226252, 4},
+		{"square", 1, 2, 5.226252, 4},
+		// higher than wide, the horizontal field of view is narrower and decides
+		{"tall", 0.5, 2, 9.861787, 8},
+		{"tiny", 1, 0.001, 0.1, 0.002},
+	}
+	for _, test := range tests {
+		for _, projection := range []camera.Projection{camera.Perspective, camera.Orthographic} {
+			c := camera.New()
+			c.Aspect = test.aspect
+			c.Projection = projection
+			o := NewOrbitController()
+			o.Activate(&c, Input{})
+
+			o.FrameSelected(&c, center, test.radius)
+			if o.Target != center || math.Abs(o.Distance-test.distance) > 1e-5 {
+				t.Errorf("%s: orbiting %g around %v, want %g around %v", test.name, o.Distance, o.Target, test.distance, center)
+			}
+			toCenter := center.Sub(c.CameraPos)
+			if math.Abs(float64(toCenter.Len())-test.distance) > 1e-4 || toCenter.Normalize().Sub(c.CameraFront).Len() > 1e-4 {
+				t.Errorf("%s: the camera at %v doesn't look at the centre from %g", test.name, c.CameraPos, test.distance)
+			}
+			if projection == camera.Orthographic && math.Abs(c.OrthoHeight-test.orthoHeight) > 1e-9 {
+				t.Errorf("%s: orthographic height %g, want %g", test.name, c.OrthoHeight, test.orthoHeight)
+			}
+		}
+	}
+}
+
+func TestOrbitScroll(t *testing.T) {
+	tests := []struct {
+		name     string
+		yoff     float64
+		distance float64
+	}{
+		{"in", 1, 4.5},
+		{"out", -1, 5 / 0.9},
+		{"none", 0, 5},
+		{"past the minimum", 100, 0.1},
+		{"past the maximum", -1000, 1000},
+	}
+	for _, test := range tests {
+		c := camera.New()
+		o := NewOrbitController()
+		o.Activate(&c, Input{})
+		fov := c.Fov
+
+		o.Scroll(&c, 0, test.yoff)
+		if math.Abs(o.Distance-test.distance) > 1e-9 {
+			t.Errorf("%s: distance %g, want %g", test.name, o.Distance, test.distance)
+		}
+		if distance := c.CameraPos.Sub(o.Target).Len(); math.Abs(float64(distance)-test.distance) > test.distance*1e-5 {
+			t.Errorf("%s: the camera is %g from the target, want %g", test.name, distance, test.distance)
+		}
+		// dollying doesn't zoom as well
+		if c.Fov != fov {
+			t.Errorf("%s: field of view changed to %g", test.name, c.Fov)
+		}
+	}
+
+	// with an orthographic projection moving closer changes nothing, so scrolling zooms as well
+	c := camera.New()
+	c.Projection = camera.Orthographic
+	o := NewOrbitController()
+	o.Activate(&c, Input{})
+	height := c.OrthoHeight
+	o.Scroll(&c, 0, 1)
+	if want := height * 0.9; math.Abs(c.OrthoHeight-want) > 1e-9 || math.Abs(o.Distance-4.5) > 1e-9 {
+		t.Errorf("orthographic height %g and distance %g, want %g and 4.5", c.OrthoHeight, o.Distance, want)
+	}
+}
@@ -0,0 +1,234 @@
+package graphics
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/PetrusJPrinsloo/learnopengl/asset"
+	"github.com/PetrusJPrinsloo/learnopengl/imageformat"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// CubemapTextureOptions clamp the faces to their edges and filter them linearly without mipmaps, which suits
+// a skybox. Cube map faces are stored top row first, so they aren't flipped.
+func CubemapTextureOptions() TextureOptions {
+	return TextureOptions{
+		WrapS:     gl.CLAMP_TO_EDGE,
+		WrapT:     gl.CLAMP_TO_EDGE,
+		MinFilter: gl.LINEAR,
+		MagFilter: gl.LINEAR,
+	}
+}
+
+// LoadCubemap loads the image assets of the +X, -X, +Y, -Y, +Z and -Z faces into a new cube map texture.
+// The faces have to be square and of the same size.
+func LoadCubemap(faces [6]string, options TextureOptions) (*Texture, error) {
+	images := make([]*imageformat.Image, len(faces))
+	for i, name := range faces {
+		data, err := asset.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("cube map face %q: %w", name, err)
+		}
+		img, err := decodeTexture(data)
+		if err != nil {
+			return nil, fmt.Errorf("cube map face %q: %w", name, err)
+		}
+		if img.Width != img.Height {
+			return nil, fmt.Errorf("cube map face %q is %dx%d, faces have to be square", name, img.Width, img.Height)
+		}
+		if i > 0 && (img.Width != images[0].Width || len(img.Levels) != len(images[0].Levels) || img.InternalFormat != images[0].InternalFormat) {
+			return nil, fmt.Errorf("cube map face %q differs in size, mipmaps or format from %q", name, faces[0])
+		}
+		images[i] = img
+	}
+	return newCubemap(images, options), nil
+}
+
+// LoadEquirectCubemap loads an equirectangular panorama, like the usual HDR environment maps, and projects it onto
+// the faces of a new cube map texture of size by size pixels. The conversion runs on the CPU. Float images stay
+// floats, the rest ends up as 8 bit RGBA.
+func LoadEquirectCubemap(name string, size int, options TextureOptions) (*Texture, error) {
+	data, err := asset.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("panorama %q: %w", name, err)
+	}
+	img, err := decodeTexture(data)
+	if err != nil {
+		return nil, fmt.Errorf("panorama %q: %w", name, err)
+	}
+	pixels, channels, err := imageFloats(img)
+	if err != nil {
+		return nil, fmt.Errorf("panorama %q: %w", name, err)
+	}
+
+	faces := make([]*imageformat.Image, 6)
+	for face := range faces {
+		facePixels := equirectFace(pixels, img.Width, img.Height, channels, face, size)
+		faces[face] = floatsImage(facePixels, size, channels, img.Type == imageformat.Float)
+	}
+	return newCubemap(faces, options), nil
+}
+
+// newCubemap uploads the six faces into a new cube map texture.
+func newCubemap(faces []*imageformat.Image, options TextureOptions) *Texture {
+	texture := &Texture{
+		Target: gl.TEXTURE_CUBE_MAP,
+		Width:  int32(faces[0].Width),
+		Height: int32(faces[0].Height),
+	}
+	gl.GenTextures(1, &texture.Id)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, texture.Id)
+
+	for i, face := range faces {
+		uploadImage(gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(i), face, options)
+	}
+	if faces[0].Compressed() && len(faces[0].Levels) == 1 {
+		options.Mipmaps = false
+	}
+	setTextureParameters(gl.TEXTURE_CUBE_MAP, options, len(faces[0].Levels))
+	return texture
+}
+
+// imageFloats returns the first mipmap level of an uncompressed 8 bit or float image as floats, top row first,
+// and the number of channels per pixel.
+func imageFloats(img *imageformat.Image) ([]float32, int, error) {
+	channels := 0
+	switch img.Format {
+	case imageformat.RGB:
+		channels = 3
+	case imageformat.RGBA:
+		channels = 4
+	}
+	if channels == 0 || (img.Type != imageformat.UByte && img.Type != imageformat.Float) {
+		return nil, 0, fmt.Errorf("only uncompressed 8 bit and 32 bit float RGB and RGBA images can be converted")
+	}
+
+	data := img.Levels[0]
+	stride := len(data) / img.Height
+	pixels := make([]float32, 0, img.Width*img.Height*channels)
+	for y := 0; y < img.Height; y++ {
+		row := y
+		if !img.TopDown {
+			row = img.Height - 1 - y
+		}
+		for i := 0; i < img.Width*channels; i++ {
+			if img.Type == imageformat.Float {
+				pixels = append(pixels, math.Float32frombits(binary.LittleEndian.Uint32(data[row*stride+i*4:])))
+			} else {
+				pixels = append(pixels, float32(data[row*stride+i])/255)
+			}
+		}
+	}
+	return pixels, channels, nil
+}
+
+// floatsImage packs the pixels of a square face into an image, as floats or as 8 bit values.
+func floatsImage(pixels []float32, size, channels int, float bool) *imageformat.Image {
+	img := &imageformat.Image{Width: size, Height: size, Format: imageformat.RGB, TopDown: true}
+	if channels == 4 {
+		img.Format = imageformat.RGBA
+	}
+
+	if float {
+		img.InternalFormat, img.Type = imageformat.RGB32F, imageformat.Float
+		if channels == 4 {
+			img.InternalFormat = imageformat.RGBA32F
+		}
+		data := make([]byte, len(pixels)*4)
+		for i, value := range pixels {
+			binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(value))
+		}
+		img.Levels = [][]byte{data}
+		return img
+	}
+
+	// 8 bit RGB rows don't always fill the 4 byte unpack alignment, so always store RGBA
+	img.InternalFormat, img.Format, img.Type = imageformat.RGBA8, imageformat.RGBA, imageformat.UByte
+	data := make([]byte, 0, size*size*4)
+	for i := 0; i < len(pixels); i += channels {
+		for c := 0; c < 4; c++ {
+			value := float32(1)
+			if c < channels {
+				value = pixels[i+c]
+			}
+			data = append(data, uint8(mgl.Clamp(value, 0, 1)*255+0.5))
+		}
+	}
+	img.Levels = [][]byte{data}
+	return img
+}
+
+// equirectFace projects an equirectangular image of width by height pixels with channels floats per pixel onto
+// face (0 to 5 for +X, -X, +Y, -Y, +Z and -Z) of a cube map, returning size by size pixels top row first.
+// The centre of the panorama ends up in the -Z direction, the one the camera starts out looking along.
+func equirectFace(pixels []float32, width, height, channels, face, size int) []float32 {
+	result := make([]float32, 0, size*size*channels)
+	sample := make([]float32, channels)
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			direction := cubemapDirection(face, (float64(i)+0.5)/float64(size)*2-1, (float64(j)+0.5)/float64(size)*2-1)
+
+			// longitude and latitude of the direction, with the top row of the panorama looking straight up
+			u := math.Atan2(direction[0], -direction[2])/(2*math.Pi) + 0.5
+			v := 0.5 - math.Asin(direction[1])/math.Pi
+			bilinear(pixels, width, height, channels, u*float64(width)-0.5, v*float64(height)-0.5, sample)
+			result = append(result, sample...)
+		}
+	}
+	return result
+}
+
+// cubemapDirection returns the unit direction a texel of a cube map face looks along, following the face
+// orientation of the OpenGL specification. s and t run from -1 to 1 across the face, t downwards.
+func cubemapDirection(face int, s, t float64) [3]float64 {
+	var d [3]float64
+	switch face {
+	case 0:
+		d = [3]float64{1, -t, -s}
+	case 1:
+		d = [3]float64{-1, -t, s}
+	case 2:
+		d = [3]float64{s, 1, t}
+	case 3:
+		d = [3]float64{s, -1, -t}
+	case 4:
+		d = [3]float64{s, -t, 1}
+	default:
+		d = [3]float64{-s, -t, -1}
+	}
+	length := math.Sqrt(d[0]*d[0] + d[1]*d[1] + d[2]*d[2])
+	return [3]float64{d[0] / length, d[1] / length, d[2] / length}
+}
+
+// bilinear samples the image at pixel coordinates x, y into result, wrapping around horizontally and clamping
+// vertically.
+func bilinear(pixels []float32, width, height, channels int, x, y float64, result []float32) {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := float32(x-x0), float32(y-y0)
+
+	column := func(x int) int {
+		return ((x % width) + width) % width
+	}
+	row := func(y int) int {
+		if y < 0 {
+			return 0
+		}
+		if y >= height {
+			return height - 1
+		}
+		return y
+	}
+	left, right := column(int(x0)), column(int(x0)+1)
+	top, bottom := row(int(y0)), row(int(y0)+1)
+
+	for c := 0; c < channels; c++ {
+		at := func(x, y int) float32 {
+			return pixels[(y*width+x)*channels+c]
+		}
+		upper := at(left, top)*(1-fx) + at(right, top)*fx
+		lower := at(left, bottom)*(1-fx) + at(right, bottom)*fx
+		result[c] = upper*(1-fy) + lower*fy
+	}
+}
@@ -1,138 +1,50 @@
 package graphics
 
 import (
-	"fmt"
 	"github.com/PetrusJPrinsloo/learnopengl/config"
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/inkyblackness/imgui-go/v2"
-	"image"
-	"image/draw"
-	_ "image/jpeg"
-	_ "image/png"
 	"log"
-	"os"
 )
 
-func MakeObjectVao(vertices []float32, program uint32) (uint32, uint32) {
-	var (
-		vbo uint32
-		vao uint32
-	)
-
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-
-	// bind the Vertex Array Object first, then bind and set vertex buffer(s), and then configure vertex attributes(s).
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	vertAttrib := uint32(gl.GetAttribLocation(program, gl.Str("aPos\x00")))
-	gl.EnableVertexAttribArray(vertAttrib)
-	gl.VertexAttribPointer(vertAttrib, 3, gl.FLOAT, false, 8*4, gl.PtrOffset(0))
-
-	normal := uint32(gl.GetAttribLocation(program, gl.Str("aNormal\x00")))
-	gl.EnableVertexAttribArray(normal)
-	gl.VertexAttribPointer(normal, 3, gl.FLOAT, false, 8*4, gl.PtrOffset(3*4))
-
-	texCoordAttrib := uint32(gl.GetAttribLocation(program, gl.Str("aTexCoords\x00")))
-	gl.EnableVertexAttribArray(texCoordAttrib)
-	gl.VertexAttribPointer(texCoordAttrib, 2, gl.FLOAT, false, 8*4, gl.PtrOffset(6*4))
-
-	return vao, vbo
-}
-
-func MakeLightVao(vertices []float32, program uint32, vbo uint32) uint32 {
-	var vao uint32
-
-	gl.GenVertexArrays(1, &vao)
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	vertAttrib := uint32(gl.GetAttribLocation(program, gl.Str("aPos\x00")))
-	gl.EnableVertexAttribArray(vertAttrib)
-	gl.VertexAttribPointer(vertAttrib, 3, gl.FLOAT, false, 8*4, gl.PtrOffset(0))
-
-	return vao
-}
-
-func MakeTexture(path string) uint32 {
-
-	rgba := loadTextureImage(path)
-
-	var texture uint32
-	gl.GenTextures(1, &texture)
-	gl.BindTexture(gl.TEXTURE_2D, texture)
-
-	// set the texture wrapping parameters
-	gl.TextureParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
-	gl.TextureParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
-
-	// set texture filtering parameters
-	gl.TextureParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TextureParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-
-	gl.TexImage2D(
-		gl.TEXTURE_2D,
-		0,
-		gl.RGBA,
-		int32(rgba.Rect.Size().X),
-		int32(rgba.Rect.Size().Y),
-		0,
-		gl.RGBA,
-		gl.UNSIGNED_BYTE,
-		gl.Ptr(rgba.Pix))
-	gl.GenerateMipmap(gl.TEXTURE_2D)
-
-	return texture
-}
-
-func loadTextureImage(path string) *image.RGBA {
-	imgFile, err := os.Open(path)
-	if err != nil {
-		panic(fmt.Errorf("texture %q not found on disk: %v", path, err))
-	}
-	img, _, err := image.Decode(imgFile)
-	if err != nil {
-		panic(err)
-	}
-
-	rgba := image.NewRGBA(img.Bounds())
-	if rgba.Stride != rgba.Rect.Size().X*4 {
-		panic(fmt.Errorf("unsupported stride"))
-	}
-	draw.Draw(rgba, rgba.Bounds(), img, image.Point{X: 0, Y: 0}, draw.Src)
-	return rgba
-}
-
-// initGlfw initializes glfw and returns a Window to use.
 func InitGlfw(io imgui.IO, cnf *config.Config) (*GLFW, error) {
 	if err := glfw.Init(); err != nil {
 		panic(err)
 	}
-	glfw.WindowHint(glfw.Resizable, glfw.False)
+	glfw.WindowHint(glfw.Resizable, glfwBool(cnf.Resizable))
+	glfw.WindowHint(glfw.Samples, cnf.Samples)
 	glfw.WindowHint(glfw.ContextVersionMajor, 3)
 	glfw.WindowHint(glfw.ContextVersionMinor, 3)
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
 	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
 
-	window, err := glfw.CreateWindow(cnf.Width, cnf.Height, "Learn OpenGL", nil, nil)
+	monitor, err := FindMonitor(cnf.Monitor)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	window.MakeContextCurrent()
-	glfw.SwapInterval(1)
 
+	window, err := glfw.CreateWindow(cnf.Width, cnf.Height, cnf.Title, nil, nil)
+	if err != nil {
+		panic(err)
+	}
 	window.MakeContextCurrent()
-	glfw.SwapInterval(1)
+	if cnf.VSync {
+		glfw.SwapInterval(1)
+	} else {
+		glfw.SwapInterval(0)
+	}
 
 	platform := &GLFW{
-		ImguiIO: io,
-		Window:  window,
+		ImguiIO:        io,
+		Window:         window,
+		windowMode:     config.Windowed,
+		fullscreenMode: cnf.WindowMode,
+		monitor:        monitor,
+		fullscreenSize: [2]int{cnf.Width, cnf.Height},
+	}
+	if cnf.WindowMode == config.Windowed {
+		platform.fullscreenMode = config.Borderless
 	}
 	platform.setKeyMapping()
 	platform.installCallbacks()
@@ -140,6 +52,14 @@ func InitGlfw(io imgui.IO, cnf *config.Config) (*GLFW, error) {
 	return platform, nil
 }
 
+// glfwBool converts b to the glfw.True or glfw.False window hints take.
+func glfwBool(b bool) int {
+	if b {
+		return glfw.True
+	}
+	return glfw.False
+}
+
 // InitOpenGL initializes OpenGL and returns an initialized program.
 func InitOpenGL() {
 	if err := gl.Init(); err != nil {
@@ -2,6 +2,7 @@ package graphics
 
 import (
 	"fmt"
+	"github.com/PetrusJPrinsloo/learnopengl/config"
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/inkyblackness/imgui-go/v2"
@@ -37,6 +38,16 @@ type GLFW struct {
 
 	time             float64
 	mouseJustPressed [3]bool
+
+	resizeListeners []func(width, height int)
+
+	windowMode config.WindowMode
+	// fullscreenMode is the mode ToggleFullscreen switches to from windowed mode
+	fullscreenMode config.WindowMode
+	monitor        *glfw.Monitor
+	fullscreenSize [2]int
+	// windowed holds the position and size to restore when leaving fullscreen
+	windowed [4]int
 }
 
 // Platform covers mouse/keyboard/gamepad inputs, cursor shape, timing, windowing.
@@ -175,6 +186,24 @@ func (platform *GLFW) installCallbacks() {
 	platform.Window.SetScrollCallback(platform.mouseScrollChange)
 	platform.Window.SetKeyCallback(platform.keyChange)
 	platform.Window.SetCharCallback(platform.charChange)
+	platform.Window.SetFramebufferSizeCallback(platform.framebufferSizeChange)
+}
+
+// AddResizeListener registers a function called with the new size in pixels whenever the framebuffer changes size,
+// to recreate whatever depends on it, like render targets. It isn't called while the window is minimized.
+func (platform *GLFW) AddResizeListener(listener func(width, height int)) {
+	platform.resizeListeners = append(platform.resizeListeners, listener)
+}
+
+func (platform *GLFW) framebufferSizeChange(window *glfw.Window, width, height int) {
+	// a minimized window has no framebuffer, keep everything the way it was for when it comes back
+	if width == 0 || height == 0 {
+		return
+	}
+	gl.Viewport(0, 0, int32(width), int32(height))
+	for _, listener := range platform.resizeListeners {
+		listener(width, height)
+	}
 }
 
 var glfwButtonIndexByID = map[glfw.MouseButton]int{
@@ -205,6 +234,9 @@ func (platform *GLFW) keyChange(window *glfw.Window, key glfw.Key, scancode int,
 	if action == glfw.Press {
 		platform.ImguiIO.KeyPress(int(key))
 	}
+	if key == glfw.KeyF11 && action == glfw.Press {
+		platform.ToggleFullscreen()
+	}
 	if action == glfw.Release {
 		platform.ImguiIO.KeyRelease(int(key))
 	}
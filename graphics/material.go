@@ -0,0 +1,79 @@
+package graphics
+
+import (
+	"fmt"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"sort"
+)
+
+// Material is how a surface is drawn: the shader, the textures it samples and its scalar parameters.
+// Textures, Floats and Bools are keyed by the name of the uniform they set, like "material.diffuse".
+type Material struct {
+	Name   string
+	Shader *Shader
+
+	Textures map[string]*Texture
+	Floats   map[string]float32
+	Bools    map[string]bool
+}
+
+// NewMaterial returns an empty material drawn with shader.
+func NewMaterial(name string, shader *Shader) *Material {
+	return &Material{
+		Name:     name,
+		Shader:   shader,
+		Textures: map[string]*Texture{},
+		Floats:   map[string]float32{},
+		Bools:    map[string]bool{},
+	}
+}
+
+// Bind makes the material's shader current, binds its textures to consecutive texture units, points the samplers
+// at them and sets the parameters. The units are handed out in the order of the sampler names, so a material
+// always ends up with the same ones.
+func (m *Material) Bind() {
+	m.Shader.Use()
+
+	samplers := make([]string, 0, len(m.Textures))
+	for sampler := range m.Textures {
+		samplers = append(samplers, sampler)
+	}
+	sort.Strings(samplers)
+
+	for unit, sampler := range samplers {
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(unit))
+		texture := m.Textures[sampler]
+		gl.BindTexture(texture.Target, texture.Id)
+		m.Shader.SetInt(sampler, int32(unit))
+	}
+	for name, value := range m.Floats {
+		m.Shader.SetFloat(name, value)
+	}
+	for name, value := range m.Bools {
+		m.Shader.SetBool(name, value)
+	}
+}
+
+// MaterialRegistry holds the materials of a scene by name.
+type MaterialRegistry struct {
+	materials map[string]*Material
+}
+
+// NewMaterialRegistry returns an empty registry.
+func NewMaterialRegistry() *MaterialRegistry {
+	return &MaterialRegistry{materials: map[string]*Material{}}
+}
+
+// Register adds a material. Names have to be unique.
+func (r *MaterialRegistry) Register(m *Material) error {
+	if _, ok := r.materials[m.Name]; ok {
+		return fmt.Errorf("material %q is already registered", m.Name)
+	}
+	r.materials[m.Name] = m
+	return nil
+}
+
+// Get returns the material called name, or nil if there is none.
+func (r *MaterialRegistry) Get(name string) *Material {
+	return r.materials[name]
+}
@@ -0,0 +1,104 @@
+package graphics
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// Mesh is geometry on the GPU: a vertex array object with its vertex buffer and, for indexed meshes, element buffer.
+type Mesh struct {
+	Vao uint32
+	Vbo uint32
+	// Ebo is 0 for meshes drawn without indices.
+	Ebo uint32
+
+	Layout      VertexLayout
+	VertexCount int32
+	IndexCount  int32
+	// IndexType is gl.UNSIGNED_SHORT when every index fits in 16 bits, gl.UNSIGNED_INT otherwise.
+	IndexType uint32
+
+	// shared meshes use the buffers of another mesh, which deletes them
+	shared bool
+}
+
+// NewMesh uploads vertices laid out as layout says and points the attributes of program at them.
+// indices may be nil, the vertices are then drawn as a list of triangles.
+func NewMesh(layout VertexLayout, vertices []float32, indices []uint32, program uint32) *Mesh {
+	return NewMeshData(layout, vertices, len(vertices)*4, indices, program)
+}
+
+// NewMeshData is NewMesh for vertex data that isn't all floats, like colours packed in bytes.
+// data is anything gl.Ptr accepts and size its length in bytes.
+func NewMeshData(layout VertexLayout, data interface{}, size int, indices []uint32, program uint32) *Mesh {
+	m := &Mesh{Layout: layout, VertexCount: int32(size) / layout.Stride()}
+
+	gl.GenVertexArrays(1, &m.Vao)
+	gl.BindVertexArray(m.Vao)
+
+	gl.GenBuffers(1, &m.Vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.Vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, size, gl.Ptr(data), gl.STATIC_DRAW)
+
+	if len(indices) > 0 {
+		m.IndexCount = int32(len(indices))
+		gl.GenBuffers(1, &m.Ebo)
+		// the element buffer binding is part of the vertex array state
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, m.Ebo)
+
+		if m.VertexCount <= 1<<16 {
+			// halves the size of the buffer for the small meshes most are
+			m.IndexType = gl.UNSIGNED_SHORT
+			short := make([]uint16, len(indices))
+			for i, index := range indices {
+				short[i] = uint16(index)
+			}
+			gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(short)*2, gl.Ptr(short), gl.STATIC_DRAW)
+		} else {
+			m.IndexType = gl.UNSIGNED_INT
+			gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
+		}
+	}
+
+	layout.Bind(program)
+	gl.BindVertexArray(0)
+	return m
+}
+
+// Share returns a mesh drawing the same buffers with the attributes of another program,
+// like the lamps drawing the cube of the objects with the light shader.
+func (m *Mesh) Share(program uint32) *Mesh {
+	shared := *m
+	shared.shared = true
+
+	gl.GenVertexArrays(1, &shared.Vao)
+	gl.BindVertexArray(shared.Vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.Vbo)
+	if m.Ebo != 0 {
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, m.Ebo)
+	}
+	m.Layout.Bind(program)
+	gl.BindVertexArray(0)
+	return &shared
+}
+
+// Draw draws the mesh as triangles with the program in use.
+func (m *Mesh) Draw() {
+	gl.BindVertexArray(m.Vao)
+	if m.Ebo != 0 {
+		gl.DrawElements(gl.TRIANGLES, m.IndexCount, m.IndexType, gl.PtrOffset(0))
+	} else {
+		gl.DrawArrays(gl.TRIANGLES, 0, m.VertexCount)
+	}
+}
+
+// Delete frees the vertex array and, unless the mesh was shared from another one, the buffers.
+func (m *Mesh) Delete() {
+	gl.DeleteVertexArrays(1, &m.Vao)
+	if m.shared {
+		return
+	}
+	gl.DeleteBuffers(1, &m.Vbo)
+	if m.Ebo != 0 {
+		gl.DeleteBuffers(1, &m.Ebo)
+	}
+}
@@ -0,0 +1,153 @@
+package graphics
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/PetrusJPrinsloo/learnopengl/shape"
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// Model is a shape.Model on the GPU: its meshes in the TangentLayout, each placed by the node it hangs from and
+// drawn with the material the model file gives it.
+type Model struct {
+	Parts []ModelPart
+	// Min and Max are the corners of the box around the model.
+	Min mgl.Vec3
+	Max mgl.Vec3
+
+	meshes []*Mesh
+	// colorTextures are the one pixel textures standing in for the maps a material doesn't have
+	colorTextures []*Texture
+}
+
+// ModelPart is a mesh of a Model with its transform relative to the model and its material.
+type ModelPart struct {
+	Mesh      *Mesh
+	Transform mgl.Mat4
+	Material  *Material
+}
+
+// NewModel uploads the meshes of model for shader and makes its materials, loading their textures through textures
+// with options. Meshes without tangents get them generated, so normal maps work on every model. Colours stand in
+// for the texture maps a material doesn't have, and meshes without a material are white.
+func NewModel(model *shape.Model, shader *Shader, textures *TextureCache, options TextureOptions) (*Model, error) {
+	m := &Model{}
+	m.Min, m.Max = model.Bounds()
+
+	materials := map[string]*Material{}
+	for _, data := range model.Meshes {
+		if _, ok := materials[data.Material]; ok {
+			continue
+		}
+		material, ok := model.Materials[data.Material]
+		if !ok {
+			material = shape.Material{Name: data.Material, Diffuse: mgl.Vec3{1, 1, 1}}
+		}
+		var err error
+		if materials[data.Material], err = m.material(material, model.Images, shader, textures, options); err != nil {
+			m.Delete()
+			return nil, fmt.Errorf("material %q: %w", data.Material, err)
+		}
+	}
+
+	for _, data := range model.Meshes {
+		if data.Stride != shape.TangentStride {
+			data = shape.GenerateTangents(data)
+		}
+		m.meshes = append(m.meshes, NewMesh(TangentLayout, data.Vertices, data.Indices, shader.Id))
+	}
+
+	// a mesh used by several nodes is drawn once for each of them
+	for node, transform := range model.WorldTransforms() {
+		for _, index := range model.Nodes[node].Meshes {
+			m.Parts = append(m.Parts, ModelPart{
+				Mesh:      m.meshes[index],
+				Transform: transform,
+				Material:  materials[model.Meshes[index].Material],
+			})
+		}
+	}
+	return m, nil
+}
+
+// material turns a material of a model file into one drawn with shader. Its maps are asset names or keys of images.
+func (m *Model) material(material shape.Material, images map[string][]byte, shader *Shader, textures *TextureCache, options TextureOptions) (*Material, error) {
+	load := func(name string) (*Texture, error) {
+		if data, ok := images[name]; ok {
+			return textures.LoadData(name, data, options)
+		}
+		return textures.Load(name, options)
+	}
+
+	result := NewMaterial(material.Name, shader)
+	maps := []struct {
+		sampler string
+		name    string
+		color   mgl.Vec3
+	}{
+		{"material.diffuse", material.DiffuseMap, material.Diffuse},
+		{"material.specular", material.SpecularMap, material.Specular},
+		{"material.normal", material.NormalMap, mgl.Vec3{0.5, 0.5, 1}},
+	}
+	for _, textureMap := range maps {
+		if textureMap.name == "" {
+			result.Textures[textureMap.sampler] = m.solidColor(textureMap.color)
+			continue
+		}
+		texture, err := load(textureMap.name)
+		if err != nil {
+			return nil, err
+		}
+		result.Textures[textureMap.sampler] = texture
+	}
+
+	result.Bools["material.hasNormal"] = material.NormalMap != ""
+	result.Floats["material.shininess"] = material.Shininess
+	if material.Shininess == 0 {
+		// glTF materials have no shininess, and without one the highlight would cover the whole surface
+		result.Floats["material.shininess"] = 32
+	}
+	return result, nil
+}
+
+// solidColor returns a new one pixel texture of colour c, which the model deletes with itself.
+func (m *Model) solidColor(c mgl.Vec3) *Texture {
+	pixel := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	channel := func(value float32) uint8 {
+		return uint8(mgl.Clamp(value, 0, 1)*255 + 0.5)
+	}
+	pixel.SetRGBA(0, 0, color.RGBA{R: channel(c[0]), G: channel(c[1]), B: channel(c[2]), A: 255})
+
+	options := DefaultTextureOptions()
+	options.Mipmaps = false
+	texture := NewTexture(pixel, options)
+	m.colorTextures = append(m.colorTextures, texture)
+	return texture
+}
+
+// Draw draws the model placed by model. A material other than nil replaces the materials of the model file.
+// The shaders of the materials need a "model" uniform.
+func (m *Model) Draw(model mgl.Mat4, material *Material) {
+	for _, part := range m.Parts {
+		partMaterial := part.Material
+		if material != nil {
+			partMaterial = material
+		}
+		partMaterial.Bind()
+		partMaterial.Shader.SetMat4("model", model.Mul4(part.Transform))
+		part.Mesh.Draw()
+	}
+}
+
+// Delete frees the meshes of the model and the textures it made for its colours. The textures of the materials
+// belong to the texture cache they came from.
+func (m *Model) Delete() {
+	for _, mesh := range m.meshes {
+		mesh.Delete()
+	}
+	for _, texture := range m.colorTextures {
+		texture.Delete()
+	}
+}
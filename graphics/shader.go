@@ -2,39 +2,127 @@ package graphics
 
 import (
 	"fmt"
+	"github.com/PetrusJPrinsloo/learnopengl/asset"
+	"github.com/PetrusJPrinsloo/learnopengl/glsl"
+	"github.com/PetrusJPrinsloo/learnopengl/lighting"
 	"github.com/go-gl/gl/v3.3-core/gl"
 	mgl "github.com/go-gl/mathgl/mgl32"
+	"strconv"
 	"strings"
 )
 
 type Shader struct {
 	Id uint32
+	// Strict logs uniforms set by name that the program doesn't have or that have a different type, once per name.
+	Strict bool
+
+	// The assets the program was built from, empty for shaders built from source code.
+	VertexPath   string
+	FragmentPath string
+
+	files    []string
+	uniforms map[string]uniform
+	reported map[string]bool
+	blocks   map[string]uint32
+}
+
+// ShaderFactory compiles and links a program from vertex and fragment shader source code.
+func ShaderFactory(vertexShaderSource string, fragmentShaderSource string) (Shader, error) {
+	return newShader(
+		shaderSource{file: "vertex shader", code: vertexShaderSource},
+		shaderSource{file: "fragment shader", code: fragmentShaderSource},
+	)
+}
+
+// ShaderPreprocessor resolves the #include directives of the shaders loaded by LoadShader and defines
+// MAX_POINT_LIGHTS and MAX_SPOT_LIGHTS, so the shaders always agree with the Go side on the light limits.
+var ShaderPreprocessor = &glsl.Preprocessor{
+	Root:     "shaders",
+	ReadFile: asset.ReadFile,
+	Defines: map[string]string{
+		"MAX_POINT_LIGHTS": strconv.Itoa(lighting.MaxPointLights),
+		"MAX_SPOT_LIGHTS":  strconv.Itoa(lighting.MaxSpotLights),
+	},
+}
+
+// the lights upload themselves to a shader as plain uniforms when there's no uniform block for them
+var _ lighting.Uniforms = (*Shader)(nil)
+
+// LoadShader compiles and links a program from a vertex and a fragment shader asset, run through ShaderPreprocessor.
+// Errors name the file and quote the lines the driver complains about.
+func LoadShader(vertexPath string, fragmentPath string) (Shader, error) {
+	vertex, err := ShaderPreprocessor.Process(vertexPath)
+	if err != nil {
+		return Shader{}, err
+	}
+
+	fragment, err := ShaderPreprocessor.Process(fragmentPath)
+	if err != nil {
+		return Shader{}, err
+	}
+
+	shader, err := newShader(
+		shaderSource{file: vertexPath, code: vertex.Code, locate: vertex.Location},
+		shaderSource{file: fragmentPath, code: fragment.Code, locate: fragment.Location},
+	)
+	if err != nil {
+		return Shader{}, err
+	}
+
+	shader.VertexPath = vertexPath
+	shader.FragmentPath = fragmentPath
+	shader.files = append(vertex.Files, fragment.Files...)
+	return shader, nil
 }
 
-func ShaderFactory(vertexShaderSource string, fragmentShaderSource string) Shader {
-	vertexShader, err := compileShader(vertexShaderSource, gl.VERTEX_SHADER)
+// shaderSource is the code of a shader stage and the file it came from.
+// locate maps a line of code back to the file it was included from, if the code was preprocessed.
+type shaderSource struct {
+	file   string
+	code   string
+	locate func(line int) glsl.Location
+}
+
+func newShader(vertex shaderSource, fragment shaderSource) (Shader, error) {
+	vertexShader, err := compileShader(vertex, gl.VERTEX_SHADER)
 	if err != nil {
-		panic(err)
+		return Shader{}, err
 	}
+	defer gl.DeleteShader(vertexShader)
 
-	fragmentShader, err := compileShader(fragmentShaderSource, gl.FRAGMENT_SHADER)
+	fragmentShader, err := compileShader(fragment, gl.FRAGMENT_SHADER)
 	if err != nil {
-		panic(err)
+		return Shader{}, err
 	}
+	defer gl.DeleteShader(fragmentShader)
 
 	program := gl.CreateProgram()
 	gl.AttachShader(program, vertexShader)
 	gl.AttachShader(program, fragmentShader)
 	gl.LinkProgram(program)
 
+	// the program keeps working without them, detaching lets the deferred deletes free the shaders
+	gl.DetachShader(program, vertexShader)
+	gl.DetachShader(program, fragmentShader)
+
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		logMsg := programInfoLog(program)
+		gl.DeleteProgram(program)
+
+		return Shader{}, &ShaderError{Op: "link", Files: []string{vertex.file, fragment.file}, Log: logMsg}
+	}
+
 	shader := Shader{Id: program}
-	return shader
+	shader.introspectUniforms()
+	return shader, nil
 }
 
-func compileShader(source string, shaderType uint32) (uint32, error) {
+func compileShader(source shaderSource, shaderType uint32) (uint32, error) {
 	shader := gl.CreateShader(shaderType)
 
-	sourcePointer, free := gl.Strs(source + "\x00")
+	sourcePointer, free := gl.Strs(source.code + "\x00")
 	gl.ShaderSource(shader, 1, sourcePointer, nil)
 	free()
 	gl.CompileShader(shader)
@@ -47,18 +135,60 @@ func compileShader(source string, shaderType uint32) (uint32, error) {
 
 		logMsg := strings.Repeat("\x00", int(logLength+1))
 		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(logMsg))
-
-		return 0, fmt.Errorf("failed to compile %v: %v", source, logMsg)
+		gl.DeleteShader(shader)
+
+		return 0, &ShaderError{
+			Op:    "compile",
+			Files: []string{source.file},
+			Log:   logMsg,
+			Lines: offendingLines(source, logMsg),
+		}
 	}
 
 	return shader, nil
 }
 
+func programInfoLog(program uint32) string {
+	var logLength int32
+	gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+
+	logMsg := strings.Repeat("\x00", int(logLength+1))
+	gl.GetProgramInfoLog(program, logLength, nil, gl.Str(logMsg))
+	return logMsg
+}
+
+// Validate checks whether the program can run in the current GL state, e.g. with the textures bound right now.
+func (s *Shader) Validate() error {
+	gl.ValidateProgram(s.Id)
+
+	var status int32
+	gl.GetProgramiv(s.Id, gl.VALIDATE_STATUS, &status)
+	if status == gl.FALSE {
+		return &ShaderError{Op: "validate", Files: []string{fmt.Sprintf("program %d", s.Id)}, Log: programInfoLog(s.Id)}
+	}
+	return nil
+}
+
 func (s *Shader) Use() {
 	gl.UseProgram(s.Id)
 }
 
-func (s *Shader) setBool(name string, value bool) {
+// BindUniformBlock connects the uniform block called name to a binding point. Programs without the block are left alone.
+// The binding is remembered and restored when the shader is reloaded.
+func (s *Shader) BindUniformBlock(name string, binding uint32) {
+	if s.blocks == nil {
+		s.blocks = map[string]uint32{}
+	}
+	s.blocks[name] = binding
+
+	index := gl.GetUniformBlockIndex(s.Id, gl.Str(name+"\x00"))
+	if index == gl.INVALID_INDEX {
+		return
+	}
+	gl.UniformBlockBinding(s.Id, index, binding)
+}
+
+func (s *Shader) SetBool(name string, value bool) {
 
 	//fast convert bool to int32
 	bitSetVar := int32(0)
@@ -66,38 +196,40 @@ func (s *Shader) setBool(name string, value bool) {
 		bitSetVar = 1
 	}
 
-	gl.Uniform1i(gl.GetUniformLocation(s.Id, gl.Str(name+"\x00")), bitSetVar)
+	gl.Uniform1i(s.location(name, gl.BOOL, gl.INT), bitSetVar)
 }
 
 // Wrapping the ugly gl function calls
+
+// SetInt sets an int or bool uniform, or points a sampler at a texture unit.
 func (s *Shader) SetInt(name string, value int32) {
-	gl.Uniform1i(gl.GetUniformLocation(s.Id, gl.Str(name+"\x00")), value)
+	gl.Uniform1i(s.location(name, gl.INT, gl.BOOL, gl.SAMPLER_2D, gl.SAMPLER_CUBE), value)
 }
 
 func (s *Shader) SetFloat(name string, value float32) {
-	gl.Uniform1f(gl.GetUniformLocation(s.Id, gl.Str(name+"\x00")), value)
+	gl.Uniform1f(s.location(name, gl.FLOAT), value)
 }
 
 func (s *Shader) SetVec2(name string, value mgl.Vec2) {
-	gl.Uniform3fv(gl.GetUniformLocation(s.Id, gl.Str(name+"\x00")), 1, &value[0])
+	gl.Uniform2fv(s.location(name, gl.FLOAT_VEC2), 1, &value[0])
 }
 
 func (s *Shader) SetVec3(name string, value mgl.Vec3) {
-	gl.Uniform3fv(gl.GetUniformLocation(s.Id, gl.Str(name+"\x00")), 1, &value[0])
+	gl.Uniform3fv(s.location(name, gl.FLOAT_VEC3), 1, &value[0])
 }
 
 func (s *Shader) SetVec4(name string, value mgl.Vec4) {
-	gl.Uniform3fv(gl.GetUniformLocation(s.Id, gl.Str(name+"\x00")), 1, &value[0])
+	gl.Uniform4fv(s.location(name, gl.FLOAT_VEC4), 1, &value[0])
 }
 
 func (s *Shader) SetMat2(name string, value mgl.Mat2) {
-	gl.UniformMatrix2fv(gl.GetUniformLocation(s.Id, gl.Str(name+"\x00")), 1, false, &value[0])
+	gl.UniformMatrix2fv(s.location(name, gl.FLOAT_MAT2), 1, false, &value[0])
 }
 
 func (s *Shader) SetMat3(name string, value mgl.Mat3) {
-	gl.UniformMatrix3fv(gl.GetUniformLocation(s.Id, gl.Str(name+"\x00")), 1, false, &value[0])
+	gl.UniformMatrix3fv(s.location(name, gl.FLOAT_MAT3), 1, false, &value[0])
 }
 
 func (s *Shader) SetMat4(name string, value mgl.Mat4) {
-	gl.UniformMatrix4fv(gl.GetUniformLocation(s.Id, gl.Str(name+"\x00")), 1, false, &value[0])
+	gl.UniformMatrix4fv(s.location(name, gl.FLOAT_MAT4), 1, false, &value[0])
 }
@@ -0,0 +1,72 @@
+package graphics
+
+import (
+	"fmt"
+	"github.com/PetrusJPrinsloo/learnopengl/glsl"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ShaderError is a shader that failed to compile or a program that failed to link.
+// It carries the info log of the driver and, for compile errors, the source lines the log complains about.
+type ShaderError struct {
+	// Op is "compile", "link" or "validate".
+	Op string
+	// Files names the shader file, or both files of a program that failed to link.
+	Files []string
+	// Log is the info log as reported by the driver.
+	Log string
+	// Lines are the offending source lines, formatted as "file:line: code" with the file they were included from.
+	Lines []string
+}
+
+func (e *ShaderError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed to %s %s:\n%s", e.Op, strings.Join(e.Files, " and "), strings.TrimRight(e.Log, "\x00\n "))
+	for _, line := range e.Lines {
+		b.WriteString("\n  ")
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// infoLogLine matches the line number in the messages of the common drivers:
+// "0:12(5): error: ..." (Mesa), "ERROR: 0:12: ..." (AMD, Intel) and "0(12) : error C0000: ..." (NVIDIA).
+var infoLogLine = regexp.MustCompile(`(?m)^\s*(?:ERROR:\s*|WARNING:\s*)?\d+[:(](\d+)`)
+
+// infoLogLines returns the source line numbers mentioned in an info log, sorted and without duplicates.
+func infoLogLines(log string) []int {
+	seen := map[int]bool{}
+	var lines []int
+	for _, match := range infoLogLine.FindAllStringSubmatch(log, -1) {
+		line, err := strconv.Atoi(match[1])
+		if err != nil || seen[line] {
+			continue
+		}
+		seen[line] = true
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// offendingLines quotes the lines of source the info log complains about, at their place in the original files.
+func offendingLines(source shaderSource, log string) []string {
+	sourceLines := strings.Split(source.code, "\n")
+
+	var quoted []string
+	for _, line := range infoLogLines(log) {
+		if line < 1 || line > len(sourceLines) {
+			continue
+		}
+
+		location := glsl.Location{File: source.file, Line: line}
+		if source.locate != nil {
+			location = source.locate(line)
+		}
+		quoted = append(quoted, fmt.Sprintf("%s: %s", location, strings.TrimSpace(sourceLines[line-1])))
+	}
+	return quoted
+}
@@ -0,0 +1,53 @@
+package graphics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PetrusJPrinsloo/learnopengl/glsl"
+)
+
+func TestInfoLogLines(t *testing.T) {
+	tests := []struct {
+		driver string
+		log    string
+		lines  []int
+	}{
+		{"Mesa", "0:12(5): error: `FragColour' undeclared\n0:3(1): warning: extension not supported", []int{3, 12}},
+		{"AMD", "ERROR: 0:7: 'x' : undeclared identifier\nERROR: 1 compilation errors.  No code generated.", []int{7}},
+		{"Intel", "WARNING: 0:2: 'precision' : unused\nERROR: 0:9: '' : syntax error", []int{2, 9}},
+		{"NVIDIA", "0(25) : error C0000: syntax error, unexpected '}'\n0(25) : error C1503: undefined variable", []int{25}},
+		{"no lines", "error: linking failed", nil},
+	}
+	for _, test := range tests {
+		if got := infoLogLines(test.log); !reflect.DeepEqual(got, test.lines) {
+			t.Errorf("%s: got lines %v, want %v", test.driver, got, test.lines)
+		}
+	}
+}
+
+func TestOffendingLines(t *testing.T) {
+	source := shaderSource{file: "colors.glsl", code: "#version 330 core\nvoid main()\n{\n    oops;\n}"}
+
+	// the first and the last line are quoted, lines outside the file are left out
+	got := offendingLines(source, "0:1(1): error: a\n0:5(1): error: b\n0:0(1): error: c\n0:6(1): error: d")
+	want := []string{"colors.glsl:1: #version 330 core", "colors.glsl:5: }"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// lines of preprocessed code are quoted at their place in the original file
+	source.locate = func(line int) glsl.Location {
+		return glsl.Location{File: "include/lights.glsl", Line: line + 10}
+	}
+	got = offendingLines(source, "ERROR: 0:4: 'oops' : undeclared identifier")
+	if want := []string{"include/lights.glsl:14: oops;"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	err := &ShaderError{Op: "compile", Files: []string{"colors.glsl"}, Log: "0:4(5): error\x00", Lines: got}
+	if message := err.Error(); !strings.HasSuffix(message, "0:4(5): error\n  include/lights.glsl:14: oops;") {
+		t.Errorf("error %q doesn't end with the log and the quoted line", message)
+	}
+}
@@ -0,0 +1,119 @@
+package graphics
+
+import (
+	"github.com/PetrusJPrinsloo/learnopengl/asset"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"log"
+	"time"
+)
+
+// DefaultPollInterval is how often a ShaderManager looks at the shader files unless told otherwise.
+const DefaultPollInterval = 500 * time.Millisecond
+
+// ShaderManager loads shaders from files and rebuilds them when the files or the files they include change,
+// so shaders can be edited while the application is running. A rebuilt shader replaces the program of the
+// *Shader in place. If the new version fails to compile, the error is logged and the old program keeps running.
+type ShaderManager struct {
+	// PollInterval is how often Poll looks at the modification times of the files.
+	PollInterval time.Duration
+
+	shaders  []*watchedShader
+	lastPoll time.Time
+}
+
+type watchedShader struct {
+	shader   *Shader
+	modTimes map[string]time.Time
+}
+
+// NewShaderManager returns a manager polling its files every DefaultPollInterval.
+func NewShaderManager() *ShaderManager {
+	return &ShaderManager{PollInterval: DefaultPollInterval}
+}
+
+// Load builds a shader from a vertex and a fragment shader asset and starts watching their files.
+func (m *ShaderManager) Load(vertexPath string, fragmentPath string) (*Shader, error) {
+	shader, err := LoadShader(vertexPath, fragmentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	watched := &watchedShader{shader: &shader}
+	watched.modTimes = watched.currentModTimes()
+	m.shaders = append(m.shaders, watched)
+	return &shader, nil
+}
+
+// Poll rebuilds the shaders whose files changed since they were last built. It has to be called on the
+// thread owning the GL context, typically once per frame; it only looks at the files every PollInterval.
+func (m *ShaderManager) Poll() {
+	now := time.Now()
+	if now.Sub(m.lastPoll) < m.PollInterval {
+		return
+	}
+	m.lastPoll = now
+
+	for _, watched := range m.shaders {
+		modTimes := watched.currentModTimes()
+		if !watched.changed(modTimes) {
+			continue
+		}
+		// remember the new times even if the build fails, so a broken file is only reported once per save
+		watched.modTimes = modTimes
+
+		if err := watched.shader.reload(); err != nil {
+			log.Printf("keeping the previous version of the shader: %v", err)
+			continue
+		}
+		// the includes may have changed, watch the files the new version was built from
+		watched.modTimes = watched.currentModTimes()
+		log.Printf("reloaded shader %s, %s", watched.shader.VertexPath, watched.shader.FragmentPath)
+	}
+}
+
+// Dispose deletes the programs of every shader loaded by the manager.
+func (m *ShaderManager) Dispose() {
+	for _, watched := range m.shaders {
+		gl.DeleteProgram(watched.shader.Id)
+	}
+	m.shaders = nil
+}
+
+func (w *watchedShader) currentModTimes() map[string]time.Time {
+	modTimes := map[string]time.Time{}
+	for _, name := range w.shader.files {
+		// a file that can't be read right now, e.g. while an editor is saving it, counts as unchanged
+		if modTime, err := asset.ModTime(name); err == nil {
+			modTimes[name] = modTime
+		}
+	}
+	return modTimes
+}
+
+func (w *watchedShader) changed(modTimes map[string]time.Time) bool {
+	for path, modTime := range modTimes {
+		if !modTime.Equal(w.modTimes[path]) {
+			return true
+		}
+	}
+	return false
+}
+
+// reload rebuilds the shader from its files and swaps the new program in, keeping the uniform block bindings.
+// The files are replaced too, as includes may have been added or removed.
+func (s *Shader) reload() error {
+	rebuilt, err := LoadShader(s.VertexPath, s.FragmentPath)
+	if err != nil {
+		return err
+	}
+
+	gl.DeleteProgram(s.Id)
+	s.Id = rebuilt.Id
+	s.files = rebuilt.files
+	s.uniforms = rebuilt.uniforms
+	s.reported = rebuilt.reported
+	for name, binding := range s.blocks {
+		s.BindUniformBlock(name, binding)
+	}
+	return nil
+}
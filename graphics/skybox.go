@@ -0,0 +1,29 @@
+package graphics
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// Skybox draws a cube map around the camera, behind everything else. Mesh is a cube around the origin drawn
+// with Shader, which samples Texture in the direction of each vertex.
+type Skybox struct {
+	Texture *Texture
+	Shader  *Shader
+	Mesh    *Mesh
+}
+
+// Draw draws the skybox. Call it after the opaque geometry: the shader puts the box on the far plane, and with
+// the depth test passing at equal depth it only fills the pixels nothing else was drawn on.
+func (s *Skybox) Draw() {
+	var depthFunc int32
+	gl.GetIntegerv(gl.DEPTH_FUNC, &depthFunc)
+	gl.DepthFunc(gl.LEQUAL)
+
+	s.Shader.Use()
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(s.Texture.Target, s.Texture.Id)
+	s.Shader.SetInt("skybox", 0)
+	s.Mesh.Draw()
+
+	gl.DepthFunc(uint32(depthFunc))
+}
@@ -0,0 +1,275 @@
+package graphics
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/PetrusJPrinsloo/learnopengl/asset"
+	"github.com/PetrusJPrinsloo/learnopengl/imageformat"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"image"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// Texture is a texture object on the GPU.
+type Texture struct {
+	Id uint32
+	// Target is what the texture binds to, like gl.TEXTURE_2D.
+	Target uint32
+	Width  int32
+	Height int32
+}
+
+// Delete frees the texture.
+func (t *Texture) Delete() {
+	gl.DeleteTextures(1, &t.Id)
+}
+
+// TextureOptions control how an image is uploaded and sampled.
+type TextureOptions struct {
+	// WrapS and WrapT are the wrap modes, like gl.REPEAT or gl.CLAMP_TO_EDGE.
+	WrapS int32
+	WrapT int32
+	// MinFilter and MagFilter are the filters, like gl.LINEAR. A mipmap filter without Mipmaps falls back
+	// to the same filter without mipmaps.
+	MinFilter int32
+	MagFilter int32
+	Mipmaps   bool
+	// Anisotropy is the maximum anisotropic filtering, 1 or less turns it off. It is capped to what the driver
+	// supports and ignored without the GL_EXT_texture_filter_anisotropic extension.
+	Anisotropy float32
+	// SRGB stores the image as sRGB, so the colours read linear in the shader. Use it for colour maps,
+	// not for data like normal or specular maps.
+	SRGB bool
+	// FlipY turns the image upside down, putting its bottom row at texture coordinate v = 0 like OpenGL expects.
+	FlipY bool
+}
+
+// DefaultTextureOptions repeat the texture and filter it trilinearly.
+func DefaultTextureOptions() TextureOptions {
+	return TextureOptions{
+		WrapS:     gl.REPEAT,
+		WrapT:     gl.REPEAT,
+		MinFilter: gl.LINEAR_MIPMAP_LINEAR,
+		MagFilter: gl.LINEAR,
+		Mipmaps:   true,
+		FlipY:     true,
+	}
+}
+
+// LoadTexture loads the image asset called name into a new texture. Besides PNG and JPEG images it reads the
+// HDR, DDS and KTX files of the imageformat package.
+func LoadTexture(name string, options TextureOptions) (*Texture, error) {
+	data, err := asset.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("texture %q: %w", name, err)
+	}
+	texture, err := NewTextureFromData(data, options)
+	if err != nil {
+		return nil, fmt.Errorf("texture %q: %w", name, err)
+	}
+	return texture, nil
+}
+
+// NewTextureFromData creates a texture from an encoded image held in memory, like the images stored in model files.
+func NewTextureFromData(data []byte, options TextureOptions) (*Texture, error) {
+	img, err := decodeTexture(data)
+	if err != nil {
+		return nil, err
+	}
+	return NewTextureFromImage(img, options), nil
+}
+
+// NewTexture uploads an image into a new 2D texture.
+func NewTexture(rgba *image.RGBA, options TextureOptions) *Texture {
+	return NewTextureFromImage(rgbaImage(rgba), options)
+}
+
+// NewTextureFromImage uploads an image read by the imageformat package into a new 2D texture, together with the
+// mipmap levels it holds. Block compressed data can't be flipped, so FlipY is ignored for it and the image has to
+// be stored bottom row first. Float images keep their float format, SRGB only applies to 8 bit and BC1, 2, 3
+// and 7 images.
+func NewTextureFromImage(img *imageformat.Image, options TextureOptions) *Texture {
+	texture := &Texture{
+		Target: gl.TEXTURE_2D,
+		Width:  int32(img.Width),
+		Height: int32(img.Height),
+	}
+	gl.GenTextures(1, &texture.Id)
+	gl.BindTexture(gl.TEXTURE_2D, texture.Id)
+
+	uploadImage(gl.TEXTURE_2D, img, options)
+	if img.Compressed() && len(img.Levels) == 1 {
+		// drivers don't reliably build mipmaps for compressed formats
+		options.Mipmaps = false
+	}
+	setTextureParameters(gl.TEXTURE_2D, options, len(img.Levels))
+	return texture
+}
+
+// uploadImage uploads every mipmap level of img to target of the bound texture.
+func uploadImage(target uint32, img *imageformat.Image, options TextureOptions) {
+	internalFormat := img.InternalFormat
+	if options.SRGB {
+		internalFormat = img.SRGBFormat()
+	}
+	for level, data := range img.Levels {
+		width, height := img.LevelSize(level)
+		if img.Compressed() {
+			gl.CompressedTexImage2D(target, int32(level), internalFormat, int32(width), int32(height), 0, int32(len(data)), gl.Ptr(data))
+			continue
+		}
+		if options.FlipY && img.TopDown {
+			data = flipRows(data, height)
+		}
+		// the parsers pad rows to 4 bytes, the default unpack alignment
+		gl.TexImage2D(target, int32(level), int32(internalFormat), int32(width), int32(height), 0, img.Format, img.Type, gl.Ptr(data))
+	}
+}
+
+// setTextureParameters applies the sampling options to the texture bound to target. levels is the number of
+// mipmap levels already uploaded, if there is only one and options ask for mipmaps they are generated.
+func setTextureParameters(target uint32, options TextureOptions, levels int) {
+	gl.TexParameteri(target, gl.TEXTURE_WRAP_S, options.WrapS)
+	gl.TexParameteri(target, gl.TEXTURE_WRAP_T, options.WrapT)
+	if target == gl.TEXTURE_CUBE_MAP {
+		// cube maps have a third coordinate, it wraps like the second
+		gl.TexParameteri(target, gl.TEXTURE_WRAP_R, options.WrapT)
+	}
+
+	minFilter := options.MinFilter
+	switch {
+	case options.Mipmaps && levels > 1:
+		// the image brings its own mipmaps, the chain may stop before 1x1
+		gl.TexParameteri(target, gl.TEXTURE_MAX_LEVEL, int32(levels-1))
+	case options.Mipmaps:
+		gl.GenerateMipmap(target)
+	default:
+		// a mipmap filter on a texture without mipmaps makes it incomplete, and it would sample black
+		switch minFilter {
+		case gl.NEAREST_MIPMAP_NEAREST, gl.NEAREST_MIPMAP_LINEAR:
+			minFilter = gl.NEAREST
+		case gl.LINEAR_MIPMAP_NEAREST, gl.LINEAR_MIPMAP_LINEAR:
+			minFilter = gl.LINEAR
+		}
+	}
+	gl.TexParameteri(target, gl.TEXTURE_MIN_FILTER, minFilter)
+	gl.TexParameteri(target, gl.TEXTURE_MAG_FILTER, options.MagFilter)
+
+	if options.Anisotropy > 1 && glfw.ExtensionSupported("GL_EXT_texture_filter_anisotropic") {
+		var max float32
+		gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY, &max)
+		anisotropy := options.Anisotropy
+		if anisotropy > max {
+			anisotropy = max
+		}
+		gl.TexParameterf(target, gl.TEXTURE_MAX_ANISOTROPY, anisotropy)
+	}
+}
+
+// decodeTexture decodes any of the supported image files.
+func decodeTexture(data []byte) (*imageformat.Image, error) {
+	if imageformat.Detect(data) {
+		return imageformat.Decode(data)
+	}
+
+	rgba, err := decodeTextureImage(data)
+	if err != nil {
+		return nil, err
+	}
+	return rgbaImage(rgba), nil
+}
+
+// rgbaImage describes the pixels of rgba as an 8 bit RGBA image of the imageformat package.
+func rgbaImage(rgba *image.RGBA) *imageformat.Image {
+	return &imageformat.Image{
+		Width:          rgba.Rect.Dx(),
+		Height:         rgba.Rect.Dy(),
+		InternalFormat: imageformat.RGBA8,
+		Format:         imageformat.RGBA,
+		Type:           imageformat.UByte,
+		Levels:         [][]byte{rgba.Pix},
+		TopDown:        true,
+	}
+}
+
+// decodeTextureImage decodes a PNG or JPEG image into the RGBA layout textures are uploaded in.
+func decodeTextureImage(data []byte) (*image.RGBA, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	rgba := image.NewRGBA(img.Bounds())
+	if rgba.Stride != rgba.Rect.Size().X*4 {
+		return nil, fmt.Errorf("unsupported stride")
+	}
+	draw.Draw(rgba, rgba.Bounds(), img, image.Point{X: 0, Y: 0}, draw.Src)
+	return rgba, nil
+}
+
+// flipRows returns a copy of the pixel data of an image with the given number of rows turned upside down.
+func flipRows(data []byte, rows int) []byte {
+	flipped := make([]byte, len(data))
+	stride := len(data) / rows
+	for y := 0; y < rows; y++ {
+		copy(flipped[y*stride:(y+1)*stride], data[(rows-1-y)*stride:(rows-y)*stride])
+	}
+	return flipped
+}
+
+// TextureCache loads every texture once, so materials sharing an image share the texture.
+// Textures are told apart by their name and options.
+type TextureCache struct {
+	textures map[textureKey]*Texture
+}
+
+type textureKey struct {
+	name    string
+	options TextureOptions
+}
+
+// NewTextureCache returns an empty cache.
+func NewTextureCache() *TextureCache {
+	return &TextureCache{textures: map[textureKey]*Texture{}}
+}
+
+// Load returns the texture made from the image asset called name, loading it the first time it is asked for.
+func (c *TextureCache) Load(name string, options TextureOptions) (*Texture, error) {
+	key := textureKey{name, options}
+	if texture, ok := c.textures[key]; ok {
+		return texture, nil
+	}
+
+	texture, err := LoadTexture(name, options)
+	if err != nil {
+		return nil, err
+	}
+	c.textures[key] = texture
+	return texture, nil
+}
+
+// LoadData is Load for images held in memory, like the ones stored in model files. name identifies the image.
+func (c *TextureCache) LoadData(name string, data []byte, options TextureOptions) (*Texture, error) {
+	key := textureKey{name, options}
+	if texture, ok := c.textures[key]; ok {
+		return texture, nil
+	}
+
+	texture, err := NewTextureFromData(data, options)
+	if err != nil {
+		return nil, fmt.Errorf("texture %q: %w", name, err)
+	}
+	c.textures[key] = texture
+	return texture, nil
+}
+
+// Delete frees every texture in the cache.
+func (c *TextureCache) Delete() {
+	for key, texture := range c.textures {
+		texture.Delete()
+		delete(c.textures, key)
+	}
+}
@@ -0,0 +1,114 @@
+package graphics
+
+import (
+	"fmt"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"log"
+	"strings"
+)
+
+// uniform is an active uniform of a linked program.
+type uniform struct {
+	location int32
+	glType   uint32
+}
+
+// introspectUniforms asks the linked program for its active uniforms and caches their locations, so the Set
+// methods don't have to look them up by name every frame. Uniforms inside uniform blocks have no location
+// and are skipped.
+func (s *Shader) introspectUniforms() {
+	s.uniforms = map[string]uniform{}
+	s.reported = map[string]bool{}
+
+	var count, maxLength int32
+	gl.GetProgramiv(s.Id, gl.ACTIVE_UNIFORMS, &count)
+	gl.GetProgramiv(s.Id, gl.ACTIVE_UNIFORM_MAX_LENGTH, &maxLength)
+	if maxLength == 0 {
+		return
+	}
+
+	nameBuffer := make([]uint8, maxLength)
+	for i := int32(0); i < count; i++ {
+		var length, size int32
+		var glType uint32
+		gl.GetActiveUniform(s.Id, uint32(i), maxLength, &length, &size, &glType, &nameBuffer[0])
+		name := string(nameBuffer[:length])
+
+		location := gl.GetUniformLocation(s.Id, gl.Str(name+"\x00"))
+		if location < 0 {
+			continue
+		}
+		s.uniforms[name] = uniform{location: location, glType: glType}
+
+		// Arrays are reported by their first element only, e.g. "weights[0]" with a size of 4.
+		// Register the bare name and every other element as well.
+		if strings.HasSuffix(name, "[0]") {
+			base := strings.TrimSuffix(name, "[0]")
+			s.uniforms[base] = uniform{location: location, glType: glType}
+			for element := int32(1); element < size; element++ {
+				elementName := fmt.Sprintf("%s[%d]", base, element)
+				elementLocation := gl.GetUniformLocation(s.Id, gl.Str(elementName+"\x00"))
+				s.uniforms[elementName] = uniform{location: elementLocation, glType: glType}
+			}
+		}
+	}
+}
+
+// location returns the cached location of the uniform called name, or -1 if the program has no such uniform
+// or its type is not one of glTypes. GL ignores values set at location -1. In strict mode, both cases are
+// logged, once per name.
+func (s *Shader) location(name string, glTypes ...uint32) int32 {
+	u, ok := s.uniforms[name]
+	if !ok {
+		s.report(name, "program %d has no active uniform %q", s.Id, name)
+		return -1
+	}
+
+	for _, glType := range glTypes {
+		if u.glType == glType {
+			return u.location
+		}
+	}
+	s.report(name, "uniform %q of program %d is a %s, not a %s", name, s.Id, glslTypeName(u.glType), glslTypeName(glTypes[0]))
+	return -1
+}
+
+func (s *Shader) report(name string, format string, v ...interface{}) {
+	if !s.Strict || s.reported[name] {
+		return
+	}
+	if s.reported == nil {
+		s.reported = map[string]bool{}
+	}
+	s.reported[name] = true
+	log.Printf(format, v...)
+}
+
+// glslTypeName returns the GLSL name of the uniform types the Set methods deal with.
+func glslTypeName(glType uint32) string {
+	switch glType {
+	case gl.BOOL:
+		return "bool"
+	case gl.INT:
+		return "int"
+	case gl.FLOAT:
+		return "float"
+	case gl.FLOAT_VEC2:
+		return "vec2"
+	case gl.FLOAT_VEC3:
+		return "vec3"
+	case gl.FLOAT_VEC4:
+		return "vec4"
+	case gl.FLOAT_MAT2:
+		return "mat2"
+	case gl.FLOAT_MAT3:
+		return "mat3"
+	case gl.FLOAT_MAT4:
+		return "mat4"
+	case gl.SAMPLER_2D:
+		return "sampler2D"
+	case gl.SAMPLER_CUBE:
+		return "samplerCube"
+	}
+	return fmt.Sprintf("type 0x%X", glType)
+}
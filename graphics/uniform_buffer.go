@@ -0,0 +1,65 @@
+package graphics
+
+import (
+	"github.com/PetrusJPrinsloo/learnopengl/std140"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// uniformBlockBindings hands out a binding point per uniform block name, so every buffer and every program
+// agree on where a block lives.
+var uniformBlockBindings = map[string]uint32{}
+
+// UniformBlockBinding returns the binding point reserved for the uniform block called name.
+func UniformBlockBinding(name string) uint32 {
+	binding, ok := uniformBlockBindings[name]
+	if !ok {
+		binding = uint32(len(uniformBlockBindings))
+		uniformBlockBindings[name] = binding
+	}
+	return binding
+}
+
+// UniformBuffer is a uniform buffer object backing the uniform block called Name. The data is uploaded once
+// per frame and shared by every program the block is bound to.
+type UniformBuffer struct {
+	Id      uint32
+	Name    string
+	Binding uint32
+
+	size int
+}
+
+// NewUniformBuffer creates the buffer for the uniform block called name and attaches it to the block's binding point.
+func NewUniformBuffer(name string) *UniformBuffer {
+	u := &UniformBuffer{
+		Name:    name,
+		Binding: UniformBlockBinding(name),
+	}
+
+	gl.GenBuffers(1, &u.Id)
+	gl.BindBufferBase(gl.UNIFORM_BUFFER, u.Binding, u.Id)
+
+	return u
+}
+
+// Update uploads the packed data, growing the buffer if it no longer fits.
+func (u *UniformBuffer) Update(data *std140.Buffer) {
+	gl.BindBuffer(gl.UNIFORM_BUFFER, u.Id)
+	if data.Len() > u.size {
+		u.size = data.Len()
+		gl.BufferData(gl.UNIFORM_BUFFER, u.size, gl.Ptr(data.Bytes()), gl.DYNAMIC_DRAW)
+	} else if data.Len() > 0 {
+		gl.BufferSubData(gl.UNIFORM_BUFFER, 0, data.Len(), gl.Ptr(data.Bytes()))
+	}
+	gl.BindBuffer(gl.UNIFORM_BUFFER, 0)
+}
+
+// Bind connects the uniform block in the shader to this buffer. Shaders that don't declare the block are left alone.
+func (u *UniformBuffer) Bind(s *Shader) {
+	s.BindUniformBlock(u.Name, u.Binding)
+}
+
+// Delete frees the buffer.
+func (u *UniformBuffer) Delete() {
+	gl.DeleteBuffers(1, &u.Id)
+}
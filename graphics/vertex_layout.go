@@ -0,0 +1,88 @@
+package graphics
+
+import (
+	"fmt"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// VertexAttribute is one attribute of interleaved vertex data, found in the shaders by Name.
+type VertexAttribute struct {
+	Name       string
+	Components int32
+	// Type is the type of a component, like gl.FLOAT or gl.UNSIGNED_BYTE.
+	Type uint32
+	// Normalized maps integer components to [0, 1] or [-1, 1] instead of converting them to floats as they are.
+	Normalized bool
+}
+
+// VertexLayout lists the attributes of interleaved vertex data in the order they appear in a vertex.
+type VertexLayout []VertexAttribute
+
+// StandardLayout is the layout of shape.Cube and the loaded models: position, normal and texture coordinates.
+var StandardLayout = VertexLayout{
+	{Name: "aPos", Components: 3, Type: gl.FLOAT},
+	{Name: "aNormal", Components: 3, Type: gl.FLOAT},
+	{Name: "aTexCoords", Components: 2, Type: gl.FLOAT},
+}
+
+// TangentLayout is StandardLayout followed by a tangent with its handedness in w, the layout of the generated shapes.
+var TangentLayout = VertexLayout{
+	{Name: "aPos", Components: 3, Type: gl.FLOAT},
+	{Name: "aNormal", Components: 3, Type: gl.FLOAT},
+	{Name: "aTexCoords", Components: 2, Type: gl.FLOAT},
+	{Name: "aTangent", Components: 4, Type: gl.FLOAT},
+}
+
+// Stride returns the size of a vertex in bytes.
+func (l VertexLayout) Stride() int32 {
+	var stride int32
+	for _, attribute := range l {
+		stride += attribute.size()
+	}
+	return stride
+}
+
+// Offset returns where the attribute called name starts in a vertex, in bytes, or -1 if there is none.
+func (l VertexLayout) Offset(name string) int {
+	offset := 0
+	for _, attribute := range l {
+		if attribute.Name == name {
+			return offset
+		}
+		offset += int(attribute.size())
+	}
+	return -1
+}
+
+// Bind points the attributes of program at the vertex buffer bound to gl.ARRAY_BUFFER, in the bound vertex array.
+// Attributes the program doesn't use are skipped, so one layout serves every shader drawing a mesh.
+func (l VertexLayout) Bind(program uint32) {
+	stride := l.Stride()
+	offset := 0
+	for _, attribute := range l {
+		location := gl.GetAttribLocation(program, gl.Str(attribute.Name+"\x00"))
+		if location >= 0 {
+			gl.EnableVertexAttribArray(uint32(location))
+			gl.VertexAttribPointer(uint32(location), attribute.Components, attribute.Type, attribute.Normalized, stride, gl.PtrOffset(offset))
+		}
+		offset += int(attribute.size())
+	}
+}
+
+func (a VertexAttribute) size() int32 {
+	return a.Components * componentSize(a.Type)
+}
+
+func componentSize(glType uint32) int32 {
+	switch glType {
+	case gl.BYTE, gl.UNSIGNED_BYTE:
+		return 1
+	case gl.SHORT, gl.UNSIGNED_SHORT, gl.HALF_FLOAT:
+		return 2
+	case gl.INT, gl.UNSIGNED_INT, gl.FLOAT, gl.FIXED:
+		return 4
+	case gl.DOUBLE:
+		return 8
+	}
+	panic(fmt.Errorf("unknown vertex component type 0x%x", glType))
+}
@@ -0,0 +1,86 @@
+package graphics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PetrusJPrinsloo/learnopengl/config"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// FindMonitor returns the monitor called name, or the one at that index in the list of connected monitors if name
+// is a number. An empty name is the primary monitor.
+func FindMonitor(name string) (*glfw.Monitor, error) {
+	if name == "" {
+		return glfw.GetPrimaryMonitor(), nil
+	}
+
+	monitors := glfw.GetMonitors()
+	if index, err := strconv.Atoi(name); err == nil {
+		if index < 0 || index >= len(monitors) {
+			return nil, fmt.Errorf("there is no monitor %d, %d are connected", index, len(monitors))
+		}
+		return monitors[index], nil
+	}
+
+	names := make([]string, len(monitors))
+	for i, monitor := range monitors {
+		names[i] = monitor.GetName()
+		if strings.EqualFold(names[i], name) {
+			return monitor, nil
+		}
+	}
+	return nil, fmt.Errorf("there is no monitor %q, the connected ones are %q", name, names)
+}
+
+// WindowMode returns how the window is shown.
+func (platform *GLFW) WindowMode() config.WindowMode {
+	return platform.windowMode
+}
+
+// SetWindowMode switches the window between windowed, borderless and exclusive fullscreen on the monitor set
+// with SetMonitor. Leaving windowed mode remembers the position and size of the window, going back restores them.
+func (platform *GLFW) SetWindowMode(mode config.WindowMode) {
+	if mode == platform.windowMode {
+		return
+	}
+	if platform.windowMode == config.Windowed {
+		x, y := platform.Window.GetPos()
+		width, height := platform.Window.GetSize()
+		platform.windowed = [4]int{x, y, width, height}
+	}
+
+	videoMode := platform.monitor.GetVideoMode()
+	switch mode {
+	case config.Windowed:
+		platform.Window.SetMonitor(nil, platform.windowed[0], platform.windowed[1], platform.windowed[2], platform.windowed[3], 0)
+	case config.Borderless:
+		// asking for the current video mode keeps the monitor as it is, the window just covers it
+		platform.Window.SetMonitor(platform.monitor, 0, 0, videoMode.Width, videoMode.Height, videoMode.RefreshRate)
+	case config.Fullscreen:
+		platform.Window.SetMonitor(platform.monitor, 0, 0, platform.fullscreenSize[0], platform.fullscreenSize[1], glfw.DontCare)
+	}
+	platform.windowMode = mode
+}
+
+// ToggleFullscreen switches between windowed mode and the fullscreen mode the window was configured with,
+// borderless if it was configured windowed.
+func (platform *GLFW) ToggleFullscreen() {
+	if platform.windowMode != config.Windowed {
+		platform.SetWindowMode(config.Windowed)
+		return
+	}
+	platform.SetWindowMode(platform.fullscreenMode)
+}
+
+// SetMonitor picks the monitor the fullscreen modes use. A window that is fullscreen already moves over.
+func (platform *GLFW) SetMonitor(monitor *glfw.Monitor) {
+	platform.monitor = monitor
+	if mode := platform.windowMode; mode != config.Windowed {
+		// switch through windowed mode to apply the new monitor, keeping the saved window position
+		platform.Window.SetMonitor(nil, platform.windowed[0], platform.windowed[1], platform.windowed[2], platform.windowed[3], 0)
+		platform.windowMode = config.Windowed
+		platform.SetWindowMode(mode)
+	}
+}
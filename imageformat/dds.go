@@ -0,0 +1,152 @@
+package imageformat
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+var ddsMagic = []byte("DDS ")
+
+const (
+	ddsHeaderSize = 124
+	ddsDX10Size   = 20
+
+	ddsFlagFourCC = 0x4
+	ddsFlagRGB    = 0x40
+	ddsFlagAlpha  = 0x1
+
+	ddsCaps2Cubemap = 0x200
+	ddsCaps2Volume  = 0x200000
+)
+
+// dxgiFormats are the DXGI formats of the DX10 header the parser understands, with their bytes per pixel
+// for the uncompressed ones.
+var dxgiFormats = map[uint32]struct {
+	internalFormat, format, typ uint32
+	bytesPerPixel               int
+}{
+	2:  {RGBA32F, RGBA, Float, 16},
+	10: {RGBA16F, RGBA, HalfFloat, 8},
+	28: {RGBA8, RGBA, UByte, 4},
+	29: {SRGBA8, RGBA, UByte, 4},
+	71: {internalFormat: CompressedRGBAS3TCDXT1},
+	72: {internalFormat: CompressedSRGBAlphaS3TCDXT1},
+	74: {internalFormat: CompressedRGBAS3TCDXT3},
+	75: {internalFormat: CompressedSRGBAlphaS3TCDXT3},
+	77: {internalFormat: CompressedRGBAS3TCDXT5},
+	78: {internalFormat: CompressedSRGBAlphaS3TCDXT5},
+	80: {internalFormat: CompressedRedRGTC1},
+	81: {internalFormat: CompressedSignedRedRGTC1},
+	83: {internalFormat: CompressedRGRGTC2},
+	84: {internalFormat: CompressedSignedRGRGTC2},
+	95: {internalFormat: CompressedRGBBPTCUnsignedF},
+	96: {internalFormat: CompressedRGBBPTCSignedF},
+	98: {internalFormat: CompressedRGBABPTCUnorm},
+	99: {internalFormat: CompressedSRGBAlphaBPTC},
+}
+
+// fourCCFormats are the compressed formats of the legacy header.
+var fourCCFormats = map[string]uint32{
+	"DXT1": CompressedRGBAS3TCDXT1,
+	"DXT3": CompressedRGBAS3TCDXT3,
+	"DXT5": CompressedRGBAS3TCDXT5,
+	"ATI1": CompressedRedRGTC1,
+	"BC4U": CompressedRedRGTC1,
+	"BC4S": CompressedSignedRedRGTC1,
+	"ATI2": CompressedRGRGTC2,
+	"BC5U": CompressedRGRGTC2,
+	"BC5S": CompressedSignedRGRGTC2,
+}
+
+// DecodeDDS reads a DirectDraw Surface (.dds) file holding a 2D texture, with the legacy or the DX10 header.
+// Cubemaps, volumes and texture arrays aren't supported.
+func DecodeDDS(data []byte) (*Image, error) {
+	if len(data) < len(ddsMagic)+ddsHeaderSize {
+		return nil, fmt.Errorf("dds: file too short")
+	}
+	header := data[len(ddsMagic) : len(ddsMagic)+ddsHeaderSize]
+	field := func(offset int) uint32 {
+		return binary.LittleEndian.Uint32(header[offset:])
+	}
+	if field(0) != ddsHeaderSize {
+		return nil, fmt.Errorf("dds: bad header size %d", field(0))
+	}
+
+	img := &Image{
+		Width:   int(field(12)),
+		Height:  int(field(8)),
+		TopDown: true,
+	}
+	if img.Width <= 0 || img.Height <= 0 || img.Width > maxSize || img.Height > maxSize {
+		return nil, fmt.Errorf("dds: bad size %dx%d", img.Width, img.Height)
+	}
+	if caps2 := field(108); caps2&(ddsCaps2Cubemap|ddsCaps2Volume) != 0 {
+		return nil, fmt.Errorf("dds: cubemaps and volume textures are not supported")
+	}
+	levels := atLeastOne(int(field(24)))
+
+	offset := len(ddsMagic) + ddsHeaderSize
+	bytesPerPixel := 0
+	flags, fourCC := field(76), string(header[80:84])
+	switch {
+	case flags&ddsFlagFourCC != 0 && fourCC == "DX10":
+		if len(data) < offset+ddsDX10Size {
+			return nil, fmt.Errorf("dds: file too short for the DX10 header")
+		}
+		dx10 := data[offset : offset+ddsDX10Size]
+		offset += ddsDX10Size
+
+		dxgiFormat := binary.LittleEndian.Uint32(dx10[0:])
+		format, ok := dxgiFormats[dxgiFormat]
+		if !ok {
+			return nil, fmt.Errorf("dds: unsupported DXGI format %d", dxgiFormat)
+		}
+		// resource dimension 3 is a 2D texture
+		if dimension := binary.LittleEndian.Uint32(dx10[4:]); dimension != 3 {
+			return nil, fmt.Errorf("dds: unsupported resource dimension %d", dimension)
+		}
+		if arraySize := binary.LittleEndian.Uint32(dx10[12:]); arraySize > 1 {
+			return nil, fmt.Errorf("dds: texture arrays are not supported")
+		}
+		if miscFlags := binary.LittleEndian.Uint32(dx10[8:]); miscFlags&0x4 != 0 {
+			return nil, fmt.Errorf("dds: cubemaps are not supported")
+		}
+		img.InternalFormat, img.Format, img.Type = format.internalFormat, format.format, format.typ
+		bytesPerPixel = format.bytesPerPixel
+	case flags&ddsFlagFourCC != 0:
+		internalFormat, ok := fourCCFormats[fourCC]
+		if !ok {
+			return nil, fmt.Errorf("dds: unsupported four character code %q", fourCC)
+		}
+		img.InternalFormat = internalFormat
+	case flags&ddsFlagRGB != 0:
+		bits := field(84)
+		masks := [4]uint32{field(88), field(92), field(96), field(100)}
+		if flags&ddsFlagAlpha == 0 {
+			masks[3] = 0
+		}
+		switch {
+		case bits == 32 && masks == [4]uint32{0xff, 0xff00, 0xff0000, 0xff000000}:
+			img.InternalFormat, img.Format = RGBA8, RGBA
+		case bits == 32 && masks == [4]uint32{0xff0000, 0xff00, 0xff, 0xff000000}:
+			img.InternalFormat, img.Format = RGBA8, BGRA
+		default:
+			return nil, fmt.Errorf("dds: unsupported %d bit pixel format with masks %x", bits, masks)
+		}
+		img.Type = UByte
+		bytesPerPixel = 4
+	default:
+		return nil, fmt.Errorf("dds: unsupported pixel format flags %x", flags)
+	}
+
+	for level := 0; level < levels; level++ {
+		width, height := img.LevelSize(level)
+		size := levelBytes(img.InternalFormat, width, height, bytesPerPixel)
+		if len(data) < offset+size {
+			return nil, fmt.Errorf("dds: file too short for mipmap level %d", level)
+		}
+		img.Levels = append(img.Levels, data[offset:offset+size])
+		offset += size
+	}
+	return img, nil
+}
@@ -0,0 +1,74 @@
+package imageformat
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestDecodeDDSLegacy(t *testing.T) {
+	img, err := DecodeDDS(readSample(t, "dxt1.dds"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 8 || img.Height != 8 || !img.TopDown {
+		t.Fatalf("got %dx%d, top down %v, want 8x8 top down", img.Width, img.Height, img.TopDown)
+	}
+	if img.InternalFormat != CompressedRGBAS3TCDXT1 || !img.Compressed() || img.Format != 0 || img.Type != 0 {
+		t.Errorf("got format %#x %#x %#x, want DXT1", img.InternalFormat, img.Format, img.Type)
+	}
+	if img.SRGBFormat() != CompressedSRGBAlphaS3TCDXT1 {
+		t.Errorf("got sRGB format %#x, want %#x", img.SRGBFormat(), CompressedSRGBAlphaS3TCDXT1)
+	}
+	// 8x8 is four blocks of 8 bytes, the smaller levels take a whole block each
+	checkLevels(t, img, 32, 8, 8, 8)
+}
+
+func TestDecodeDDSDX10(t *testing.T) {
+	img, err := DecodeDDS(readSample(t, "bc7.dds"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 8 || img.Height != 4 {
+		t.Fatalf("got %dx%d, want 8x4", img.Width, img.Height)
+	}
+	if img.InternalFormat != CompressedSRGBAlphaBPTC || !img.Compressed() {
+		t.Errorf("got format %#x, want sRGB BC7", img.InternalFormat)
+	}
+	// a mipmap count of 0 is one level
+	checkLevels(t, img, 32)
+}
+
+func TestDecodeDDSErrors(t *testing.T) {
+	legacy := readSample(t, "dxt1.dds")
+	dx10 := readSample(t, "bc7.dds")
+	// change returns a copy of data with the header field at offset set to value
+	change := func(data []byte, offset int, value uint32) []byte {
+		data = append([]byte{}, data...)
+		binary.LittleEndian.PutUint32(data[offset:], value)
+		return data
+	}
+	for _, test := range []struct {
+		name string
+		data []byte
+	}{
+		{"short", legacy[:100]},
+		{"header size", change(legacy, 4, 100)},
+		{"zero width", change(legacy, 16, 0)},
+		{"huge width", change(legacy, 16, 0xffffffff)},
+		{"truncated", legacy[:len(legacy)-1]},
+		{"more levels", change(legacy, 28, 5)},
+		{"cubemap", change(legacy, 112, 0xfe00)},
+		{"four character code", change(legacy, 84, binary.LittleEndian.Uint32([]byte("XYZW")))},
+		{"no DX10 header", dx10[:130]},
+		{"DXGI format", change(dx10, 128, 1)},
+		{"texture 3D", change(dx10, 132, 4)},
+		{"array", change(dx10, 140, 6)},
+		{"DX10 truncated", dx10[:len(dx10)-1]},
+		// the size of the level would overflow to a negative number
+		{"huge size", change(change(dx10, 16, 0xfffffff0), 12, 0xfffffff0)},
+	} {
+		if _, err := DecodeDDS(test.data); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package imageformat
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// isHDR tells whether data starts with the signature of a Radiance file.
+func isHDR(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("#?RADIANCE")) || bytes.HasPrefix(data, []byte("#?RGBE"))
+}
+
+// DecodeHDR reads a Radiance RGBE (.hdr) image into 32 bit float RGB pixels.
+func DecodeHDR(data []byte) (*Image, error) {
+	r := bufio.NewReader(bytes.NewReader(data))
+
+	format := ""
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("hdr: header: %v", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "FORMAT=") {
+			format = strings.TrimPrefix(line, "FORMAT=")
+		}
+	}
+	if format != "" && format != "32-bit_rle_rgbe" {
+		return nil, fmt.Errorf("hdr: unsupported format %q", format)
+	}
+
+	resolution, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("hdr: resolution: %v", err)
+	}
+	var yOrder, xOrder string
+	var width, height int
+	if _, err := fmt.Sscanf(resolution, "%s %d %s %d", &yOrder, &height, &xOrder, &width); err != nil {
+		return nil, fmt.Errorf("hdr: resolution %q: %v", strings.TrimSpace(resolution), err)
+	}
+	if xOrder != "+X" || (yOrder != "-Y" && yOrder != "+Y") {
+		return nil, fmt.Errorf("hdr: unsupported orientation %q", strings.TrimSpace(resolution))
+	}
+	if width <= 0 || height <= 0 || width > maxSize || height > maxSize {
+		return nil, fmt.Errorf("hdr: bad size %dx%d", width, height)
+	}
+
+	pixels := make([]byte, width*height*3*4)
+	scanline := make([]byte, width*4)
+	for y := 0; y < height; y++ {
+		if err := readScanline(r, scanline, width); err != nil {
+			return nil, fmt.Errorf("hdr: scanline %d: %v", y, err)
+		}
+		row := pixels[y*width*12 : (y+1)*width*12]
+		for x := 0; x < width; x++ {
+			rgbe := scanline[x*4 : x*4+4]
+			var rgb [3]float32
+			if rgbe[3] != 0 {
+				scale := float32(math.Ldexp(1, int(rgbe[3])-(128+8)))
+				rgb = [3]float32{float32(rgbe[0]) * scale, float32(rgbe[1]) * scale, float32(rgbe[2]) * scale}
+			}
+			for c, value := range rgb {
+				binary.LittleEndian.PutUint32(row[x*12+c*4:], math.Float32bits(value))
+			}
+		}
+	}
+
+	return &Image{
+		Width:          width,
+		Height:         height,
+		InternalFormat: RGB32F,
+		Format:         RGB,
+		Type:           Float,
+		Levels:         [][]byte{pixels},
+		// "-Y" is the usual order, the top scanline comes first
+		TopDown: yOrder == "-Y",
+	}, nil
+}
+
+// readScanline reads a scanline of RGBE pixels in either the run length encoded or the flat layout.
+func readScanline(r *bufio.Reader, scanline []byte, width int) error {
+	start, err := r.Peek(4)
+	if err != nil {
+		return err
+	}
+
+	// new run length encoding: 2, 2, then the width, then every component encoded separately
+	if width < 8 || width > 0x7fff || start[0] != 2 || start[1] != 2 || start[2]&0x80 != 0 {
+		_, err := io.ReadFull(r, scanline)
+		return err
+	}
+	if int(start[2])<<8|int(start[3]) != width {
+		return fmt.Errorf("encoded width %d doesn't match the image", int(start[2])<<8|int(start[3]))
+	}
+	if _, err := r.Discard(4); err != nil {
+		return err
+	}
+
+	for component := 0; component < 4; component++ {
+		for x := 0; x < width; {
+			count, err := r.ReadByte()
+			if err != nil {
+				return err
+			}
+
+			if count > 128 {
+				// a run of the same value
+				count -= 128
+				if x+int(count) > width {
+					return fmt.Errorf("run past the end of the scanline")
+				}
+				value, err := r.ReadByte()
+				if err != nil {
+					return err
+				}
+				for i := 0; i < int(count); i++ {
+					scanline[(x+i)*4+component] = value
+				}
+			} else {
+				// count literal values
+				if count == 0 || x+int(count) > width {
+					return fmt.Errorf("bad literal run of %d", count)
+				}
+				for i := 0; i < int(count); i++ {
+					value, err := r.ReadByte()
+					if err != nil {
+						return err
+					}
+					scanline[(x+i)*4+component] = value
+				}
+			}
+			x += int(count)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package imageformat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeHDRRunLength(t *testing.T) {
+	img, err := DecodeHDR(readSample(t, "rle.hdr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 8 || img.Height != 2 || !img.TopDown {
+		t.Fatalf("got %dx%d, top down %v, want 8x2 top down", img.Width, img.Height, img.TopDown)
+	}
+	if img.InternalFormat != RGB32F || img.Format != RGB || img.Type != Float || img.Compressed() {
+		t.Errorf("got format %#x %#x %#x, want 32 bit float RGB", img.InternalFormat, img.Format, img.Type)
+	}
+	if len(img.Levels) != 1 || len(img.Levels[0]) != 8*2*3*4 {
+		t.Fatalf("got %d levels, want one of %d bytes", len(img.Levels), 8*2*3*4)
+	}
+
+	pixels := floats(img.Levels[0])
+	// the exponent of the first row scales by 1/128, the second by 1/64
+	for _, test := range []struct {
+		x, y    int
+		r, g, b float32
+	}{
+		{0, 0, 1, 0, 0},
+		{1, 0, 1, 0.5, 0},
+		{3, 0, 1, 255.0 / 128, 0},
+		{4, 0, 1, 1.0 / 128, 9.0 / 128},
+		{7, 0, 1, 4.0 / 128, 9.0 / 128},
+		{2, 1, 2, 2, 0},
+		{7, 1, 2, 4.0 / 64, 9.0 / 64},
+	} {
+		pixel := pixels[(test.y*8+test.x)*3:]
+		if pixel[0] != test.r || pixel[1] != test.g || pixel[2] != test.b {
+			t.Errorf("pixel %d,%d is %v, want %v", test.x, test.y, pixel[:3], []float32{test.r, test.g, test.b})
+		}
+	}
+}
+
+func TestDecodeHDRFlat(t *testing.T) {
+	img, err := DecodeHDR(readSample(t, "flat.hdr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 2 || img.Height != 1 || img.TopDown {
+		t.Fatalf("got %dx%d, top down %v, want 2x1 bottom up", img.Width, img.Height, img.TopDown)
+	}
+	// a zero exponent is black whatever the other bytes say
+	want := []float32{1, 0.5, 0.25, 0, 0, 0}
+	got := floats(img.Levels[0])
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestDecodeHDRErrors(t *testing.T) {
+	rle := string(readSample(t, "rle.hdr"))
+	for _, test := range []struct {
+		name string
+		data string
+	}{
+		{"no header end", "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n"},
+		{"xyze", "#?RADIANCE\nFORMAT=32-bit_rle_xyze\n\n-Y 1 +X 1\n\x00\x00\x00\x00"},
+		{"flipped", "#?RADIANCE\n\n-Y 1 -X 1\n\x00\x00\x00\x00"},
+		{"empty", "#?RADIANCE\n\n-Y 0 +X 1\n"},
+		{"huge", "#?RADIANCE\n\n-Y 1000000000 +X 1000000000\n"},
+		{"truncated", rle[:len(rle)-1]},
+		{"long run", strings.Replace(rle, "\x88\x80", "\x89\x80", 1)},
+		{"encoded width", strings.Replace(rle, "\x02\x02\x00\x08", "\x02\x02\x00\x09", 1)},
+	} {
+		if _, err := DecodeHDR([]byte(test.data)); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Package imageformat reads the texture files image.Decode doesn't handle: Radiance HDR images and DDS and KTX
+// containers with block compressed or floating point data. It is pure Go and knows nothing about OpenGL beyond
+// the enum values describing the data, so the files can be inspected without a GPU.
+package imageformat
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// OpenGL enums for the formats the parsers produce. They have the same values as the constants of the gl package.
+const (
+	RGB       = 0x1907
+	RGBA      = 0x1908
+	BGRA      = 0x80E1
+	RED       = 0x1903
+	RG        = 0x8227
+	RGBA8     = 0x8058
+	RGB16F    = 0x881B
+	RGBA16F   = 0x881A
+	RGB32F    = 0x8815
+	RGBA32F   = 0x8814
+	SRGB8     = 0x8C41
+	SRGBA8    = 0x8C43
+	UByte     = 0x1401
+	HalfFloat = 0x140B
+	Float     = 0x1406
+
+	CompressedRGBS3TCDXT1       = 0x83F0 // BC1 without alpha
+	CompressedRGBAS3TCDXT1      = 0x83F1 // BC1
+	CompressedRGBAS3TCDXT3      = 0x83F2 // BC2
+	CompressedRGBAS3TCDXT5      = 0x83F3 // BC3
+	CompressedSRGBS3TCDXT1      = 0x8C4C
+	CompressedSRGBAlphaS3TCDXT1 = 0x8C4D
+	CompressedSRGBAlphaS3TCDXT3 = 0x8C4E
+	CompressedSRGBAlphaS3TCDXT5 = 0x8C4F
+	CompressedRedRGTC1          = 0x8DBB // BC4
+	CompressedSignedRedRGTC1    = 0x8DBC
+	CompressedRGRGTC2           = 0x8DBD // BC5
+	CompressedSignedRGRGTC2     = 0x8DBE
+	CompressedRGBABPTCUnorm     = 0x8E8C // BC7
+	CompressedSRGBAlphaBPTC     = 0x8E8D
+	CompressedRGBBPTCSignedF    = 0x8E8E // BC6H
+	CompressedRGBBPTCUnsignedF  = 0x8E8F
+)
+
+// maxSize bounds the width and height of an image. It is well above what OpenGL accepts, and keeps the sizes
+// computed from a damaged header from overflowing.
+const maxSize = 1 << 16
+
+// blockSizes are the bytes per 4x4 block of the block compressed formats.
+var blockSizes = map[uint32]int{
+	CompressedRGBS3TCDXT1:       8,
+	CompressedRGBAS3TCDXT1:      8,
+	CompressedSRGBS3TCDXT1:      8,
+	CompressedSRGBAlphaS3TCDXT1: 8,
+	CompressedRedRGTC1:          8,
+	CompressedSignedRedRGTC1:    8,
+	CompressedRGBAS3TCDXT3:      16,
+	CompressedRGBAS3TCDXT5:      16,
+	CompressedSRGBAlphaS3TCDXT3: 16,
+	CompressedSRGBAlphaS3TCDXT5: 16,
+	CompressedRGRGTC2:           16,
+	CompressedSignedRGRGTC2:     16,
+	CompressedRGBABPTCUnorm:     16,
+	CompressedSRGBAlphaBPTC:     16,
+	CompressedRGBBPTCSignedF:    16,
+	CompressedRGBBPTCUnsignedF:  16,
+}
+
+// srgbFormats maps linear formats to the sRGB format with the same layout.
+var srgbFormats = map[uint32]uint32{
+	RGBA8:                   SRGBA8,
+	CompressedRGBS3TCDXT1:   CompressedSRGBS3TCDXT1,
+	CompressedRGBAS3TCDXT1:  CompressedSRGBAlphaS3TCDXT1,
+	CompressedRGBAS3TCDXT3:  CompressedSRGBAlphaS3TCDXT3,
+	CompressedRGBAS3TCDXT5:  CompressedSRGBAlphaS3TCDXT5,
+	CompressedRGBABPTCUnorm: CompressedSRGBAlphaBPTC,
+}
+
+// Image is texture data ready to be handed to glTexImage2D or glCompressedTexImage2D.
+type Image struct {
+	Width  int
+	Height int
+	// InternalFormat is the format the texture is stored in on the GPU.
+	InternalFormat uint32
+	// Format and Type describe the data of uncompressed images. Both are 0 for compressed images.
+	Format uint32
+	Type   uint32
+	// Levels holds the mipmap levels, largest first. Floats are stored in little endian byte order.
+	Levels [][]byte
+	// TopDown is set when the first row of the data is the top of the image, DDS and HDR files store it that
+	// way. OpenGL puts the first row at texture coordinate v = 0.
+	TopDown bool
+}
+
+// Compressed tells whether the image holds block compressed data.
+func (img *Image) Compressed() bool {
+	_, ok := blockSizes[img.InternalFormat]
+	return ok
+}
+
+// SRGBFormat returns the sRGB variant of the internal format, or the internal format itself if there is none,
+// like for floating point images.
+func (img *Image) SRGBFormat() uint32 {
+	if format, ok := srgbFormats[img.InternalFormat]; ok {
+		return format
+	}
+	return img.InternalFormat
+}
+
+// LevelSize returns the width and height of a mipmap level.
+func (img *Image) LevelSize(level int) (int, int) {
+	return atLeastOne(img.Width >> uint(level)), atLeastOne(img.Height >> uint(level))
+}
+
+// levelBytes returns the size of a mipmap level of width by height pixels. bytesPerPixel is ignored
+// for compressed formats.
+func levelBytes(internalFormat uint32, width, height, bytesPerPixel int) int {
+	if blockSize, ok := blockSizes[internalFormat]; ok {
+		return atLeastOne((width+3)/4) * atLeastOne((height+3)/4) * blockSize
+	}
+	return width * height * bytesPerPixel
+}
+
+// Detect tells whether data starts like one of the supported files.
+func Detect(data []byte) bool {
+	return bytes.HasPrefix(data, ddsMagic) || bytes.HasPrefix(data, ktxIdentifier) || isHDR(data)
+}
+
+// Decode reads an HDR, DDS or KTX file, telling them apart by their first bytes.
+func Decode(data []byte) (*Image, error) {
+	switch {
+	case bytes.HasPrefix(data, ddsMagic):
+		return DecodeDDS(data)
+	case bytes.HasPrefix(data, ktxIdentifier):
+		return DecodeKTX(data)
+	case isHDR(data):
+		return DecodeHDR(data)
+	}
+	return nil, fmt.Errorf("not an HDR, DDS or KTX file")
+}
+
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
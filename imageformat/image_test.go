@@ -0,0 +1,66 @@
+package imageformat
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// readSample returns the contents of a file in testdata.
+func readSample(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// floats reads the little endian floats of a mipmap level.
+func floats(level []byte) []float32 {
+	values := make([]float32, len(level)/4)
+	for i := range values {
+		values[i] = math.Float32frombits(binary.LittleEndian.Uint32(level[i*4:]))
+	}
+	return values
+}
+
+// checkLevels compares the sizes of the mipmap levels, and checks every level is filled with one byte value,
+// the samples use the index of the level plus one.
+func checkLevels(t *testing.T, img *Image, sizes ...int) {
+	t.Helper()
+	if len(img.Levels) != len(sizes) {
+		t.Fatalf("got %d levels, want %d", len(img.Levels), len(sizes))
+	}
+	for i, level := range img.Levels {
+		if len(level) != sizes[i] {
+			t.Errorf("level %d holds %d bytes, want %d", i, len(level), sizes[i])
+		}
+		for _, b := range level {
+			if b != byte(i+1) {
+				t.Errorf("level %d holds data of another level", i)
+				break
+			}
+		}
+	}
+}
+
+func TestDecodeDetects(t *testing.T) {
+	for _, name := range []string{"rle.hdr", "flat.hdr", "dxt1.dds", "bc7.dds", "rgb.ktx"} {
+		data := readSample(t, name)
+		if !Detect(data) {
+			t.Errorf("%s not detected", name)
+		}
+		if _, err := Decode(data); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if Detect([]byte("\x89PNG\r\n\x1a\n")) {
+		t.Error("PNG detected")
+	}
+	if _, err := Decode([]byte("\x89PNG\r\n\x1a\n")); err == nil {
+		t.Error("no error for a PNG")
+	}
+}
@@ -0,0 +1,95 @@
+package imageformat
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+var ktxIdentifier = []byte{0xAB, 'K', 'T', 'X', ' ', '1', '1', 0xBB, '\r', '\n', 0x1A, '\n'}
+
+const ktxHeaderSize = 64
+
+// ktxBytesPerPixel are the pixel sizes of the uncompressed formats the parser accepts, by format and type.
+var ktxBytesPerPixel = map[[2]uint32]int{
+	{RED, UByte}:      1,
+	{RG, UByte}:       2,
+	{RGB, UByte}:      3,
+	{RGBA, UByte}:     4,
+	{BGRA, UByte}:     4,
+	{RGB, HalfFloat}:  6,
+	{RGBA, HalfFloat}: 8,
+	{RGB, Float}:      12,
+	{RGBA, Float}:     16,
+}
+
+// DecodeKTX reads a KTX 1 file holding a 2D texture. Cubemaps, 3D textures and texture arrays aren't supported.
+func DecodeKTX(data []byte) (*Image, error) {
+	if len(data) < ktxHeaderSize {
+		return nil, fmt.Errorf("ktx: file too short")
+	}
+
+	// the file is written in the byte order of the machine that wrote it, the endianness field tells which
+	var order binary.ByteOrder = binary.LittleEndian
+	switch binary.LittleEndian.Uint32(data[12:]) {
+	case 0x04030201:
+	case 0x01020304:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("ktx: bad endianness field")
+	}
+	field := func(index int) uint32 {
+		return order.Uint32(data[16+index*4:])
+	}
+	glType, glFormat, glInternalFormat := field(0), field(2), field(3)
+	width, height, depth := int(field(5)), int(field(6)), field(7)
+	arrayElements, faces, levels, keyValueBytes := field(8), field(9), atLeastOne(int(field(10))), int(field(11))
+
+	if width <= 0 || width > maxSize || height > maxSize {
+		return nil, fmt.Errorf("ktx: bad size %dx%d", width, height)
+	}
+	if height == 0 || depth != 0 || arrayElements != 0 || faces != 1 {
+		return nil, fmt.Errorf("ktx: only 2D textures are supported")
+	}
+
+	img := &Image{Width: width, Height: height, InternalFormat: glInternalFormat}
+	bytesPerPixel := 0
+	if glType == 0 {
+		if !img.Compressed() {
+			return nil, fmt.Errorf("ktx: unsupported compressed format %#x", glInternalFormat)
+		}
+	} else {
+		var ok bool
+		if bytesPerPixel, ok = ktxBytesPerPixel[[2]uint32{glFormat, glType}]; !ok {
+			return nil, fmt.Errorf("ktx: unsupported format %#x with type %#x", glFormat, glType)
+		}
+		if order == binary.BigEndian && glType != UByte {
+			return nil, fmt.Errorf("ktx: big endian files with type %#x are not supported", glType)
+		}
+		img.Format, img.Type = glFormat, glType
+	}
+
+	offset := ktxHeaderSize + keyValueBytes
+	for level := 0; level < levels; level++ {
+		if len(data) < offset+4 {
+			return nil, fmt.Errorf("ktx: file too short for mipmap level %d", level)
+		}
+		size := int(order.Uint32(data[offset:]))
+		offset += 4
+
+		levelWidth, levelHeight := img.LevelSize(level)
+		expected := levelBytes(img.InternalFormat, levelWidth, levelHeight, bytesPerPixel)
+		if !img.Compressed() {
+			// uncompressed rows are padded to 4 bytes, like the default unpack alignment of OpenGL
+			expected = (levelWidth*bytesPerPixel + 3) / 4 * 4 * levelHeight
+		}
+		if size != expected {
+			return nil, fmt.Errorf("ktx: mipmap level %d holds %d bytes, expected %d", level, size, expected)
+		}
+		if len(data) < offset+size {
+			return nil, fmt.Errorf("ktx: file too short for mipmap level %d", level)
+		}
+		img.Levels = append(img.Levels, data[offset:offset+size])
+		offset += (size + 3) / 4 * 4
+	}
+	return img, nil
+}
@@ -0,0 +1,54 @@
+package imageformat
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestDecodeKTX(t *testing.T) {
+	img, err := DecodeKTX(readSample(t, "rgb.ktx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 3 || img.Height != 2 || img.TopDown {
+		t.Fatalf("got %dx%d, top down %v, want 3x2 bottom up", img.Width, img.Height, img.TopDown)
+	}
+	if img.InternalFormat != 0x8051 || img.Format != RGB || img.Type != UByte || img.Compressed() {
+		t.Errorf("got format %#x %#x %#x, want RGB8 bytes", img.InternalFormat, img.Format, img.Type)
+	}
+	// rows of 9 bytes are padded to 12, and the key/value data is skipped
+	checkLevels(t, img, 24, 4)
+}
+
+func TestDecodeKTXErrors(t *testing.T) {
+	sample := readSample(t, "rgb.ktx")
+	// change returns a copy of the sample with the header field at index set to value
+	change := func(index int, value uint32) []byte {
+		data := append([]byte{}, sample...)
+		binary.LittleEndian.PutUint32(data[16+index*4:], value)
+		return data
+	}
+	for _, test := range []struct {
+		name string
+		data []byte
+	}{
+		{"short", sample[:40]},
+		{"endianness", append(append([]byte{}, sample[:12]...), make([]byte, len(sample)-12)...)},
+		{"zero width", change(5, 0)},
+		{"huge width", change(5, 0xffffffff)},
+		{"huge size", change(6, 1<<31)},
+		{"1D", change(6, 0)},
+		{"3D", change(7, 2)},
+		{"array", change(8, 2)},
+		{"cubemap", change(9, 6)},
+		{"type", change(0, Float+1)},
+		{"compressed format", change(0, 0)},
+		{"more levels", change(10, 3)},
+		{"key/value data", change(11, 1000)},
+		{"truncated", sample[:len(sample)-1]},
+	} {
+		if _, err := DecodeKTX(test.data); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Package lighting holds the lights of the lighting shader. They pack themselves into its Lights uniform block, or
+// upload themselves to a shader as plain uniforms.
+package lighting
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/PetrusJPrinsloo/learnopengl/std140"
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// The most point and spot lights the lighting shader has room for.
+// graphics.ShaderPreprocessor passes them on to the shaders as MAX_POINT_LIGHTS and MAX_SPOT_LIGHTS.
+const (
+	MaxPointLights = 32
+	MaxSpotLights  = 32
+)
+
+// Attenuation holds the terms of the distance falloff of point and spot lights.
+type Attenuation struct {
+	Constant  float32 `json:"constant"`
+	Linear    float32 `json:"linear"`
+	Quadratic float32 `json:"quadratic"`
+}
+
+// DirLight is a light infinitely far away shining in a single direction, like the sun.
+// It maps onto the DirLight struct in the fragment shader.
+type DirLight struct {
+	Direction mgl.Vec3 `json:"direction"`
+
+	Ambient  mgl.Vec3 `json:"ambient"`
+	Diffuse  mgl.Vec3 `json:"diffuse"`
+	Specular mgl.Vec3 `json:"specular"`
+}
+
+// PointLight is a light shining in all directions from a position in the world.
+// It maps onto the PointLight struct in the fragment shader.
+type PointLight struct {
+	Position mgl.Vec3 `json:"position"`
+
+	Attenuation
+
+	Ambient  mgl.Vec3 `json:"ambient"`
+	Diffuse  mgl.Vec3 `json:"diffuse"`
+	Specular mgl.Vec3 `json:"specular"`
+}
+
+// SpotLight is a cone of light. CutOff and OuterCutOff are the angles of the inner and outer cone in degrees.
+// It maps onto the SpotLight struct in the fragment shader.
+type SpotLight struct {
+	Position    mgl.Vec3 `json:"position"`
+	Direction   mgl.Vec3 `json:"direction"`
+	CutOff      float32  `json:"cutOff"`
+	OuterCutOff float32  `json:"outerCutOff"`
+
+	Attenuation
+
+	Ambient  mgl.Vec3 `json:"ambient"`
+	Diffuse  mgl.Vec3 `json:"diffuse"`
+	Specular mgl.Vec3 `json:"specular"`
+}
+
+// Lights is everything in the Lights uniform block: the directional light and the active point and spot lights.
+type Lights struct {
+	Dir   DirLight
+	Point PointLights
+	Spot  SpotLights
+}
+
+// PointLights is a list of point lights packed into a uniform array.
+type PointLights []PointLight
+
+// SpotLights is a list of spot lights packed into a uniform array.
+type SpotLights []SpotLight
+
+// Uniforms sets the uniforms of a shader program by name. *graphics.Shader implements it.
+type Uniforms interface {
+	SetFloat(name string, value float32)
+	SetVec3(name string, value mgl.Vec3)
+}
+
+// Upload sets the uniforms of the struct called name, e.g. "dirLight".
+func (l DirLight) Upload(s Uniforms, name string) {
+	s.SetVec3(name+".direction", l.Direction)
+	s.SetVec3(name+".ambient", l.Ambient)
+	s.SetVec3(name+".diffuse", l.Diffuse)
+	s.SetVec3(name+".specular", l.Specular)
+}
+
+func (a Attenuation) upload(s Uniforms, name string) {
+	s.SetFloat(name+".constant", a.Constant)
+	s.SetFloat(name+".linear", a.Linear)
+	s.SetFloat(name+".quadratic", a.Quadratic)
+}
+
+// Upload sets the uniforms of the struct called name, e.g. "pointLights[0]".
+func (l PointLight) Upload(s Uniforms, name string) {
+	s.SetVec3(name+".position", l.Position)
+	l.Attenuation.upload(s, name)
+	s.SetVec3(name+".ambient", l.Ambient)
+	s.SetVec3(name+".diffuse", l.Diffuse)
+	s.SetVec3(name+".specular", l.Specular)
+}
+
+// Upload sets the uniforms of the struct called name, e.g. "spotLight". The cut-off angles are uploaded as cosines.
+func (l SpotLight) Upload(s Uniforms, name string) {
+	s.SetVec3(name+".position", l.Position)
+	s.SetVec3(name+".direction", l.Direction)
+	s.SetFloat(name+".cutOff", cosDeg(l.CutOff))
+	s.SetFloat(name+".outerCutOff", cosDeg(l.OuterCutOff))
+	l.Attenuation.upload(s, name)
+	s.SetVec3(name+".ambient", l.Ambient)
+	s.SetVec3(name+".diffuse", l.Diffuse)
+	s.SetVec3(name+".specular", l.Specular)
+}
+
+// Upload sets every light in the uniform array called name, e.g. "pointLights".
+func (lights PointLights) Upload(s Uniforms, name string) {
+	for i, light := range lights {
+		light.Upload(s, arrayElement(name, i))
+	}
+}
+
+// Upload sets every light in the uniform array called name, e.g. "spotLights".
+func (lights SpotLights) Upload(s Uniforms, name string) {
+	for i, light := range lights {
+		light.Upload(s, arrayElement(name, i))
+	}
+}
+
+func arrayElement(name string, index int) string {
+	return fmt.Sprintf("%s[%d]", name, index)
+}
+
+func cosDeg(degrees float32) float32 {
+	return float32(math.Cos(float64(mgl.DegToRad(degrees))))
+}
+
+// Encode packs the light as a std140 struct, for use in a uniform block.
+func (l DirLight) Encode(b *std140.Buffer) {
+	b.BeginStruct()
+	b.Vec3(l.Direction)
+	b.Vec3(l.Ambient)
+	b.Vec3(l.Diffuse)
+	b.Vec3(l.Specular)
+	b.EndStruct()
+}
+
+func (a Attenuation) encode(b *std140.Buffer) {
+	b.Float(a.Constant)
+	b.Float(a.Linear)
+	b.Float(a.Quadratic)
+}
+
+// Encode packs the light as a std140 struct, for use in a uniform block.
+func (l PointLight) Encode(b *std140.Buffer) {
+	b.BeginStruct()
+	b.Vec3(l.Position)
+	l.Attenuation.encode(b)
+	b.Vec3(l.Ambient)
+	b.Vec3(l.Diffuse)
+	b.Vec3(l.Specular)
+	b.EndStruct()
+}
+
+// Encode packs the light as a std140 struct, for use in a uniform block. The cut-off angles are packed as cosines.
+func (l SpotLight) Encode(b *std140.Buffer) {
+	b.BeginStruct()
+	b.Vec3(l.Position)
+	b.Vec3(l.Direction)
+	b.Float(cosDeg(l.CutOff))
+	b.Float(cosDeg(l.OuterCutOff))
+	l.Attenuation.encode(b)
+	b.Vec3(l.Ambient)
+	b.Vec3(l.Diffuse)
+	b.Vec3(l.Specular)
+	b.EndStruct()
+}
+
+// Validate checks that the number of point and spot lights fits in the shader.
+func (l Lights) Validate() error {
+	if len(l.Point) > MaxPointLights {
+		return fmt.Errorf("%d point lights, the shader supports at most %d", len(l.Point), MaxPointLights)
+	}
+	if len(l.Spot) > MaxSpotLights {
+		return fmt.Errorf("%d spot lights, the shader supports at most %d", len(l.Spot), MaxSpotLights)
+	}
+	return nil
+}
+
+// Encode packs the lights as the Lights uniform block. The light arrays are padded to their maximum size
+// and followed by the number of active lights, so the shader only loops over the lights that are in use.
+func (l Lights) Encode(b *std140.Buffer) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+
+	l.Dir.Encode(b)
+	for i := 0; i < MaxPointLights; i++ {
+		light := PointLight{}
+		if i < len(l.Point) {
+			light = l.Point[i]
+		}
+		light.Encode(b)
+	}
+	for i := 0; i < MaxSpotLights; i++ {
+		light := SpotLight{}
+		if i < len(l.Spot) {
+			light = l.Spot[i]
+		}
+		light.Encode(b)
+	}
+	b.Int(int32(len(l.Point)))
+	b.Int(int32(len(l.Spot)))
+	return nil
+}
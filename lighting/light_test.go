@@ -0,0 +1,184 @@
+package lighting
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/PetrusJPrinsloo/learnopengl/std140"
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+func floatAt(data []byte, offset int) float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(data[offset:]))
+}
+
+// The offsets are the ones the GLSL structs in shaders/include/lights.glsl get under std140.
+func TestLightLayout(t *testing.T) {
+	attenuation := Attenuation{Constant: 1, Linear: 2, Quadratic: 3}
+	tests := []struct {
+		name   string
+		encode func(b *std140.Buffer)
+		floats map[int]float32
+		size   int
+	}{
+		{
+			name: "DirLight",
+			encode: DirLight{
+				Direction: mgl.Vec3{1, 0, 0},
+				Ambient:   mgl.Vec3{2, 0, 0},
+				Diffuse:   mgl.Vec3{3, 0, 0},
+				Specular:  mgl.Vec3{4, 0, 0},
+			}.Encode,
+			floats: map[int]float32{0: 1, 16: 2, 32: 3, 48: 4},
+			size:   64,
+		},
+		{
+			name: "PointLight",
+			encode: PointLight{
+				Position:    mgl.Vec3{4, 0, 0},
+				Attenuation: attenuation,
+				Ambient:     mgl.Vec3{5, 0, 0},
+				Diffuse:     mgl.Vec3{6, 0, 0},
+				Specular:    mgl.Vec3{7, 0, 0},
+			}.Encode,
+			floats: map[int]float32{0: 4, 12: 1, 16: 2, 20: 3, 32: 5, 48: 6, 64: 7},
+			size:   80,
+		},
+		{
+			name: "SpotLight",
+			encode: SpotLight{
+				Position:    mgl.Vec3{4, 0, 0},
+				Direction:   mgl.Vec3{5, 0, 0},
+				CutOff:      0,
+				OuterCutOff: 90,
+				Attenuation: attenuation,
+				Ambient:     mgl.Vec3{6, 0, 0},
+				Diffuse:     mgl.Vec3{7, 0, 0},
+				Specular:    mgl.Vec3{8, 0, 0},
+			}.Encode,
+			floats: map[int]float32{0: 4, 16: 5, 28: 1, 36: 1, 40: 2, 44: 3, 48: 6, 64: 7, 80: 8},
+			size:   96,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b std140.Buffer
+			test.encode(&b)
+			if b.Len() != test.size {
+				t.Errorf("packed %d bytes, want %d", b.Len(), test.size)
+			}
+			for offset, want := range test.floats {
+				if got := floatAt(b.Bytes(), offset); got != want {
+					t.Errorf("at offset %d: got %g, want %g", offset, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLightsEncode(t *testing.T) {
+	lights := Lights{
+		Point: PointLights{{Position: mgl.Vec3{1, 0, 0}}, {Position: mgl.Vec3{2, 0, 0}}},
+		Spot:  SpotLights{{Position: mgl.Vec3{3, 0, 0}}},
+	}
+	var b std140.Buffer
+	if err := lights.Encode(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	const pointStart, pointStride = 64, 80
+	const spotStart, spotStride = pointStart + MaxPointLights*pointStride, 96
+	const countsStart = spotStart + MaxSpotLights*spotStride
+	if want := countsStart + 8; b.Len() != want {
+		t.Fatalf("packed %d bytes, want %d", b.Len(), want)
+	}
+
+	data := b.Bytes()
+	if got := floatAt(data, pointStart+pointStride); got != 2 {
+		t.Errorf("second point light at x %g, want 2", got)
+	}
+	if got := floatAt(data, spotStart); got != 3 {
+		t.Errorf("first spot light at x %g, want 3", got)
+	}
+	if got := binary.LittleEndian.Uint32(data[countsStart:]); got != 2 {
+		t.Errorf("nrPointLights is %d, want 2", got)
+	}
+	if got := binary.LittleEndian.Uint32(data[countsStart+4:]); got != 1 {
+		t.Errorf("nrSpotLights is %d, want 1", got)
+	}
+}
+
+func TestLightsEncodeTooMany(t *testing.T) {
+	lights := Lights{Point: make(PointLights, MaxPointLights+1)}
+	var b std140.Buffer
+	if err := lights.Encode(&b); err == nil {
+		t.Error("no error for more point lights than the shader supports")
+	}
+}
+
+// uniforms records the values uploaded to it by name.
+type uniforms map[string]interface{}
+
+func (u uniforms) SetFloat(name string, value float32) { u[name] = value }
+func (u uniforms) SetVec3(name string, value mgl.Vec3) { u[name] = value }
+
+func TestUpload(t *testing.T) {
+	attenuation := Attenuation{Constant: 1, Linear: 0.09, Quadratic: 0.032}
+	got := uniforms{}
+	DirLight{Direction: mgl.Vec3{0, -1, 0}, Diffuse: mgl.Vec3{0.5, 0.5, 0.5}}.Upload(got, "dirLight")
+	PointLights{
+		{Position: mgl.Vec3{1, 2, 3}, Attenuation: attenuation},
+		{Position: mgl.Vec3{4, 5, 6}, Specular: mgl.Vec3{1, 1, 1}},
+	}.Upload(got, "pointLights")
+	SpotLights{{Direction: mgl.Vec3{0, 0, -1}, CutOff: 60, OuterCutOff: 90, Attenuation: attenuation}}.Upload(got, "spotLights")
+
+	want := uniforms{
+		"dirLight.direction": mgl.Vec3{0, -1, 0},
+		"dirLight.ambient":   mgl.Vec3{},
+		"dirLight.diffuse":   mgl.Vec3{0.5, 0.5, 0.5},
+		"dirLight.specular":  mgl.Vec3{},
+
+		"pointLights[0].position":  mgl.Vec3{1, 2, 3},
+		"pointLights[0].constant":  float32(1),
+		"pointLights[0].linear":    float32(0.09),
+		"pointLights[0].quadratic": float32(0.032),
+		"pointLights[0].ambient":   mgl.Vec3{},
+		"pointLights[0].diffuse":   mgl.Vec3{},
+		"pointLights[0].specular":  mgl.Vec3{},
+		"pointLights[1].position":  mgl.Vec3{4, 5, 6},
+		"pointLights[1].constant":  float32(0),
+		"pointLights[1].linear":    float32(0),
+		"pointLights[1].quadratic": float32(0),
+		"pointLights[1].ambient":   mgl.Vec3{},
+		"pointLights[1].diffuse":   mgl.Vec3{},
+		"pointLights[1].specular":  mgl.Vec3{1, 1, 1},
+
+		"spotLights[0].position":    mgl.Vec3{},
+		"spotLights[0].direction":   mgl.Vec3{0, 0, -1},
+		"spotLights[0].cutOff":      float32(0.5),
+		"spotLights[0].outerCutOff": float32(0),
+		"spotLights[0].constant":    float32(1),
+		"spotLights[0].linear":      float32(0.09),
+		"spotLights[0].quadratic":   float32(0.032),
+		"spotLights[0].ambient":     mgl.Vec3{},
+		"spotLights[0].diffuse":     mgl.Vec3{},
+		"spotLights[0].specular":    mgl.Vec3{},
+	}
+	for name, value := range want {
+		if f, ok := value.(float32); ok {
+			// the cut-offs go through a cosine
+			if g, ok := got[name].(float32); !ok || math.Abs(float64(g-f)) > 1e-6 {
+				t.Errorf("%s is %v, want %v", name, got[name], value)
+			}
+		} else if got[name] != value {
+			t.Errorf("%s is %v, want %v", name, got[name], value)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("unexpected uniform %s", name)
+		}
+	}
+}
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"github.com/PetrusJPrinsloo/learnopengl/asset"
+	"github.com/PetrusJPrinsloo/learnopengl/camera"
 	"github.com/PetrusJPrinsloo/learnopengl/config"
 	"github.com/PetrusJPrinsloo/learnopengl/graphics"
+	"github.com/PetrusJPrinsloo/learnopengl/resources"
+	"github.com/PetrusJPrinsloo/learnopengl/scene"
 	"github.com/PetrusJPrinsloo/learnopengl/shape"
+	"github.com/PetrusJPrinsloo/learnopengl/std140"
 
 	//"github.com/PetrusJPrinsloo/learnopengl/shape"
 	mgl "github.com/go-gl/mathgl/mgl32"
 	"github.com/inkyblackness/imgui-go/v2"
-	"io/ioutil"
 	"log"
 	"math"
 	"os"
@@ -22,44 +27,61 @@ import (
 
 var cnf *config.Config
 
-var lightDirection = mgl.Vec3{-0.2, -1.0, -0.3}
-
-//var cubePosition = mgl.Vec3{0.0, 0.0, 0.0}
-
-var cubePositions = []mgl.Vec3{
-	//{ 0.0,  0.0,  0.0},
-	{2.0, 5.0, -15.0},
-	{-1.5, -2.2, -2.5},
-	{-3.8, -2.0, -12.3},
-	{2.4, -0.4, -3.5},
-	{-1.7, 3.0, -7.5},
-	{1.3, -2.0, -2.5},
-	{1.5, 2.0, -2.5},
-	{1.5, 0.2, -1.5},
-	{-1.3, 1.0, -1.5},
-}
+var scn *scene.Scene
 
-var pointLightPositions = []mgl.Vec3{
-	{0.7, 0.2, 2.0},
-	{2.3, -3.3, -4.0},
-	{-4.0, 2.0, -12.0},
-	{0.0, 0.0, -3.0},
-}
+// Uniform buffers shared by every program, and the encoders packing their data each frame
+var (
+	cameraBuffer *graphics.UniformBuffer
+	lightsBuffer *graphics.UniformBuffer
+	cameraBlock  std140.Buffer
+	lightsBlock  std140.Buffer
+)
 
-var camera = graphics.GetCamera()
+var cam = camera.New()
+
+// The controllers the camera can be driven with, C switches between them
+var (
+	fpsController   = graphics.NewFPSController()
+	orbitController = graphics.NewOrbitController()
+	// switchHeld and projectionHeld are set while their keys are down, so holding one switches only once
+	switchHeld     bool
+	projectionHeld bool
+)
+
+var controller graphics.CameraController = fpsController
 
 var deltaTime = 0.0
 var lastFrame = 0.0
 
 func main() {
-	cnf = config.ReadFile("default.json")
-	vertexShaderSource := getTextFileContents("resources\\shaders\\vertex\\colors.glsl")
-	fragmentShaderSource := getTextFileContents("resources\\shaders\\fragment\\colors.glsl")
-	vertexShaderSourceLight := getTextFileContents("resources\\shaders\\vertex\\light_cube.glsl")
-	fragmentShaderSourceLight := getTextFileContents("resources\\shaders\\fragment\\light_cube.glsl")
+	var err error
+	cnf, err = config.Load(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	asset.Default.Roots = cnf.AssetRoots
+	// nil unless built with -tags embed
+	asset.Default.Embedded = resources.FS
+
+	scn, err = scene.ReadFile(cnf.Scene)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	camera.LastX = float64(cnf.Width) / 2.0
-	camera.LastY = float64(cnf.Height) / 2.0
+	cam.LastX = float64(cnf.Width) / 2.0
+	cam.LastY = float64(cnf.Height) / 2.0
+	cam.Fov = cnf.FOV
+	cam.MaxFov = cnf.FOV
+	cam.Sensitivity = cnf.Sensitivity
+	cam.Near = cnf.Near
+	cam.Far = cnf.Far
+	if cnf.Projection == config.Orthographic {
+		cam.Projection = camera.Orthographic
+	}
+	cam.SetWorldUp(mgl.Vec3(cnf.Up))
 
 	runtime.LockOSThread()
 
@@ -74,32 +96,100 @@ func main() {
 
 	graphics.InitOpenGL()
 	defer glfw.Terminate()
-	objectShader := graphics.ShaderFactory(vertexShaderSource, fragmentShaderSource)
-	lightShader := graphics.ShaderFactory(vertexShaderSourceLight, fragmentShaderSourceLight)
+	// the window is created windowed, so there's a position and size to go back to. Switching only once OpenGL
+	// is loaded lets the framebuffer size callback set the viewport.
+	GLFW.SetWindowMode(cnf.WindowMode)
+	shaders := graphics.NewShaderManager()
+	defer shaders.Dispose()
+	objectShader, err := shaders.Load("shaders/vertex/colors.glsl", "shaders/fragment/colors.glsl")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(-1)
+	}
+	lightShader, err := shaders.Load("shaders/vertex/light_cube.glsl", "shaders/fragment/light_cube.glsl")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(-1)
+	}
+	skyboxShader, err := shaders.Load("shaders/vertex/skybox.glsl", "shaders/fragment/skybox.glsl")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(-1)
+	}
+	objectShader.Strict = true
+	lightShader.Strict = true
+	skyboxShader.Strict = true
 	objectShader.Use()
 
-	vao, vbo := graphics.MakeObjectVao(shape.Cube, objectShader.Id)
-	lightVao := graphics.MakeLightVao(shape.Cube, lightShader.Id, vbo)
+	cameraBuffer = graphics.NewUniformBuffer("Camera")
+	lightsBuffer = graphics.NewUniformBuffer("Lights")
+	defer cameraBuffer.Delete()
+	defer lightsBuffer.Delete()
+	for _, shader := range []*graphics.Shader{objectShader, lightShader, skyboxShader} {
+		cameraBuffer.Bind(shader)
+		lightsBuffer.Bind(shader)
+	}
+
+	cubeMesh, ok := shape.Generated("cube")
+	if !ok {
+		_, _ = fmt.Fprintf(os.Stderr, "there is no generated cube mesh for the lights\n")
+		os.Exit(-1)
+	}
+	lightCube := graphics.NewMesh(graphics.TangentLayout, cubeMesh.Vertices, cubeMesh.Indices, lightShader.Id)
+	defer lightCube.Delete()
 
 	// Configure global settings
 	gl.Enable(gl.DEPTH_TEST)
 	gl.DepthFunc(gl.LESS)
-	gl.ClearColor(0.126, 0.145, 0.2, 1.0)
-
-	GLFW.Window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
-	GLFW.Window.SetCursorPosCallback(camera.MouseCallback)
-	GLFW.Window.SetScrollCallback(camera.ScrollCallback)
-
-	diffuseMap := graphics.MakeTexture("resources\\textures\\container2.png")
-	objectShader.SetInt("material.diffuse", 0)
-	specularMap := graphics.MakeTexture("resources\\textures\\container2_specular.png")
-	objectShader.SetInt("material.specular", 1)
-	objectShader.SetFloat("light.constant", 1.0)
-	objectShader.SetFloat("light.linear", 0.09)
-	objectShader.SetFloat("light.quadratic", 0.032)
+	gl.ClearColor(cnf.ClearColor[0], cnf.ClearColor[1], cnf.ClearColor[2], 1.0)
+	if cnf.Samples > 0 {
+		gl.Enable(gl.MULTISAMPLE)
+	}
+	// filter across the edges of cube map faces, so the skybox has no seams
+	gl.Enable(gl.TEXTURE_CUBE_MAP_SEAMLESS)
+
+	graphics.ActivateController(controller, &cam, GLFW.Window)
+	GLFW.Window.SetCursorPosCallback(func(window *glfw.Window, x float64, y float64) {
+		input := graphics.ReadInput(window)
+		input.CursorX, input.CursorY = x, y
+		controller.MouseMove(&cam, input)
+	})
+	GLFW.Window.SetScrollCallback(func(window *glfw.Window, xoff float64, yoff float64) {
+		controller.Scroll(&cam, xoff, yoff)
+	})
+	// the projection follows the shape of the framebuffer, which is in pixels and differs from the window size
+	// on HiDPI screens
+	setAspect := func(width, height int) {
+		cam.Aspect = float64(width) / float64(height)
+	}
+	if width, height := GLFW.Window.GetFramebufferSize(); width > 0 && height > 0 {
+		setAspect(width, height)
+	}
+	GLFW.AddResizeListener(setAspect)
 
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, 0)
+	textures := graphics.NewTextureCache()
+	defer textures.Delete()
+	materials, err := loadMaterials(objectShader, textures)
+	if err != nil {
+		log.Fatal(err)
+	}
+	models, err := loadModels(objectShader, textures)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		for _, model := range models {
+			model.Delete()
+		}
+	}()
+	skybox, err := loadSkybox(skyboxShader, lightCube)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if skybox != nil {
+		defer skybox.Texture.Delete()
+		defer skybox.Mesh.Delete()
+	}
 
 	renderer, err := graphics.NewOpenGL3(io)
 	if err != nil {
@@ -110,11 +200,109 @@ func main() {
 
 	defer GLFW.Dispose()
 
-	Run(GLFW, renderer, vao, lightVao, GLFW.Window, &objectShader, &lightShader, diffuseMap, specularMap)
+	Run(GLFW, renderer, shaders, models, lightCube, GLFW.Window, lightShader, materials, skybox)
+}
+
+// loadModels uploads the meshes the objects of the scene use, by the names the objects use. The materials of
+// model files are drawn with shader.
+func loadModels(shader *graphics.Shader, textures *graphics.TextureCache) (map[string]*graphics.Model, error) {
+	models := map[string]*graphics.Model{}
+	for _, object := range scn.Objects {
+		if _, loaded := models[object.Mesh]; loaded {
+			continue
+		}
+
+		var model *shape.Model
+		if mesh, ok := shape.Generated(object.Mesh); ok {
+			model = shape.NewModel(mesh)
+		} else if shape.IsModelFile(object.Mesh) {
+			var err error
+			if model, err = shape.LoadModel(object.Mesh); err != nil {
+				return nil, fmt.Errorf("object %q: %w", object.Name, err)
+			}
+		} else {
+			return nil, fmt.Errorf("object %q uses unknown mesh %q", object.Name, object.Mesh)
+		}
+		uploaded, err := graphics.NewModel(model, shader, textures, textureOptions())
+		if err != nil {
+			return nil, fmt.Errorf("object %q: %w", object.Name, err)
+		}
+		models[object.Mesh] = uploaded
+	}
+	return models, nil
+}
+
+// loadSkybox loads the skybox selected by the config, or else by the scene, drawn with shader on a copy of cube.
+// It returns nil if there is none.
+func loadSkybox(shader *graphics.Shader, cube *graphics.Mesh) (*graphics.Skybox, error) {
+	name := scn.Skybox
+	if cnf.Skybox != "" {
+		name = cnf.Skybox
+	}
+	if name == "" || name == "none" {
+		return nil, nil
+	}
+	sceneSkybox, ok := scn.Skyboxes[name]
+	if !ok {
+		return nil, fmt.Errorf("the scene has no skybox %q", name)
+	}
+
+	var texture *graphics.Texture
+	var err error
+	if sceneSkybox.Equirect != "" {
+		texture, err = graphics.LoadEquirectCubemap(sceneSkybox.Equirect, sceneSkybox.Size, graphics.CubemapTextureOptions())
+	} else {
+		var faces [6]string
+		copy(faces[:], sceneSkybox.Faces)
+		texture, err = graphics.LoadCubemap(faces, graphics.CubemapTextureOptions())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("skybox %q: %w", name, err)
+	}
+
+	return &graphics.Skybox{Texture: texture, Shader: shader, Mesh: cube.Share(shader.Id)}, nil
+}
+
+// loadMaterials loads the textures of the scene materials and registers them, drawn with shader.
+func loadMaterials(shader *graphics.Shader, textures *graphics.TextureCache) (*graphics.MaterialRegistry, error) {
+	options := textureOptions()
+	materials := graphics.NewMaterialRegistry()
+	for name, sceneMaterial := range scn.Materials {
+		material := graphics.NewMaterial(name, shader)
+		maps := map[string]string{
+			"material.diffuse":  sceneMaterial.Diffuse,
+			"material.specular": sceneMaterial.Specular,
+			"material.normal":   sceneMaterial.Normal,
+		}
+		for sampler, image := range maps {
+			if image == "" {
+				continue
+			}
+			texture, err := textures.Load(image, options)
+			if err != nil {
+				return nil, err
+			}
+			material.Textures[sampler] = texture
+		}
+		material.Bools["material.hasNormal"] = sceneMaterial.Normal != ""
+		material.Floats["material.shininess"] = sceneMaterial.Shininess
+
+		if err := materials.Register(material); err != nil {
+			return nil, err
+		}
+	}
+	return materials, nil
+}
+
+// textureOptions are the options the textures of the materials are loaded with.
+func textureOptions() graphics.TextureOptions {
+	options := graphics.DefaultTextureOptions()
+	options.Anisotropy = 16
+	return options
 }
 
 // draw function called from application loop
-func draw(vao uint32, lightVao uint32, window *glfw.Window, objectShader *graphics.Shader, lightCubeShader *graphics.Shader, texture uint32, specularMap uint32) {
+func draw(models map[string]*graphics.Model, lightCube *graphics.Mesh, window *glfw.Window, lightCubeShader *graphics.Shader, materials *graphics.MaterialRegistry, skybox *graphics.Skybox) {
 	// per-frame time logic
 	// --------------------
 	currentFrame := glfw.GetTime()
@@ -123,111 +311,51 @@ func draw(vao uint32, lightVao uint32, window *glfw.Window, objectShader *graphi
 
 	// input
 	// -----
-	processInput(window)
+	processInput(window, models)
+
+	if width, height := window.GetFramebufferSize(); width == 0 || height == 0 {
+		// minimized, there's nothing to draw on
+		return
+	}
 
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-	objectShader.Use()
-	objectShader.SetVec3("objectColor", mgl.Vec3{1.0, 0.5, 0.31})
-	objectShader.SetVec3("lightColor", mgl.Vec3{3.0, 3.0, 3.0})
-	/*
-	   Here we set all the uniforms for the 5/6 types of lights we have. We have to set them manually and index
-	   the proper PointLight struct in the array to set each uniform variable. This can be done more code-friendly
-	   by defining light types as classes and set their values in there, or by using a more efficient uniform approach
-	   by using 'Uniform buffer objects', but that is something we'll discuss in the 'Advanced GLSL' tutorial.
-	*/
-	// directional light
-	objectShader.SetVec3("dirLight.direction", mgl.Vec3{-0.2, -1.0, -0.3})
-	objectShader.SetVec3("dirLight.ambient", mgl.Vec3{0.05, 0.05, 0.05})
-	objectShader.SetVec3("dirLight.diffuse", mgl.Vec3{0.4, 0.4, 0.4})
-	objectShader.SetVec3("dirLight.specular", mgl.Vec3{0.5, 0.5, 0.5})
-	// point light 1
-	objectShader.SetVec3("pointLights[0].position", pointLightPositions[0])
-	objectShader.SetVec3("pointLights[0].ambient", mgl.Vec3{0.05, 0.05, 0.05})
-	objectShader.SetVec3("pointLights[0].diffuse", mgl.Vec3{0.8, 0.8, 0.8})
-	objectShader.SetVec3("pointLights[0].specular", mgl.Vec3{.0, 1.0, 1.0})
-	objectShader.SetFloat("pointLights[0].constant", 1.0)
-	objectShader.SetFloat("pointLights[0].linear", 0.09)
-	objectShader.SetFloat("pointLights[0].quadratic", 0.032)
-	// point light 2
-	objectShader.SetVec3("pointLights[1].position", pointLightPositions[1])
-	objectShader.SetVec3("pointLights[1].ambient", mgl.Vec3{0.05, 0.05, 0.05})
-	objectShader.SetVec3("pointLights[1].diffuse", mgl.Vec3{0.8, 0.8, 0.8})
-	objectShader.SetVec3("pointLights[1].specular", mgl.Vec3{1.0, 1.0, 1.0})
-	objectShader.SetFloat("pointLights[1].constant", 1.0)
-	objectShader.SetFloat("pointLights[1].linear", 0.09)
-	objectShader.SetFloat("pointLights[1].quadratic", 0.032)
-	// point light 3
-	objectShader.SetVec3("pointLights[2].position", pointLightPositions[2])
-	objectShader.SetVec3("pointLights[2].ambient", mgl.Vec3{0.05, 0.05, 0.05})
-	objectShader.SetVec3("pointLights[2].diffuse", mgl.Vec3{0.8, 0.8, 0.8})
-	objectShader.SetVec3("pointLights[2].specular", mgl.Vec3{1.0, 1.0, 1.0})
-	objectShader.SetFloat("pointLights[2].constant", 1.0)
-	objectShader.SetFloat("pointLights[2].linear", 0.09)
-	objectShader.SetFloat("pointLights[2].quadratic", 0.032)
-	// point light 4
-	objectShader.SetVec3("pointLights[3].position", pointLightPositions[3])
-	objectShader.SetVec3("pointLights[3].ambient", mgl.Vec3{0.05, 0.05, 0.05})
-	objectShader.SetVec3("pointLights[3].diffuse", mgl.Vec3{0.8, 0.8, 0.8})
-	objectShader.SetVec3("pointLights[3].specular", mgl.Vec3{1.0, 1.0, 1.0})
-	objectShader.SetFloat("pointLights[3].constant", 1.0)
-	objectShader.SetFloat("pointLights[3].linear", 0.09)
-	objectShader.SetFloat("pointLights[3].quadratic", 0.032)
-	// spotLight
-	objectShader.SetVec3("spotLight.position", camera.CameraPos)
-	objectShader.SetVec3("spotLight.direction", camera.CameraFront)
-	objectShader.SetVec3("spotLight.ambient", mgl.Vec3{0.0, 0.0, 0.0})
-	objectShader.SetVec3("spotLight.diffuse", mgl.Vec3{1.0, 1.0, 1.0})
-	objectShader.SetVec3("spotLight.specular", mgl.Vec3{1.0, 1.0, 1.0})
-	objectShader.SetFloat("spotLight.constant", 1.0)
-	objectShader.SetFloat("spotLight.linear", 0.09)
-	objectShader.SetFloat("spotLight.quadratic", 0.032)
-	objectShader.SetFloat("spotLight.outerCutOff", float32(math.Cos(float64(mgl.DegToRad(15.0)))))
-	objectShader.SetFloat("spotLight.cutOff", float32(math.Cos(float64(mgl.DegToRad(12.5)))))
-
-	// material properties
-	objectShader.SetFloat("material.shininess", 32.0)
-
-	//Transformation Matrices
-	projection := mgl.Perspective(mgl.DegToRad(float32(camera.Fov)), float32(cnf.Width)/float32(cnf.Height), 0.1, 100.0)
-	objectShader.SetMat4("projection", projection)
-
-	// camera/view transformation
-	view := mgl.Ident4()
-	view = view.Mul4(mgl.LookAtV(camera.CameraPos, camera.CameraPos.Add(camera.CameraFront), camera.CameraUp))
-	objectShader.SetMat4("view", view)
-	//objectShader.SetVec3("lightPos", lightPosition)
-	objectShader.SetVec3("viewPos", camera.CameraPos)
-
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, texture)
-	gl.ActiveTexture(gl.TEXTURE1)
-	gl.BindTexture(gl.TEXTURE_2D, specularMap)
-
-	gl.BindVertexArray(vao)
-
-	for _, cubePosition := range cubePositions {
-		model := mgl.Ident4()
-		model = model.Mul4(mgl.Translate3D(cubePosition.X(), cubePosition.Y(), cubePosition.Z()))
-		objectShader.SetMat4("model", model)
+	// upload the camera and lights once, every program reads them from the uniform buffers
+	cameraBlock.Reset()
+	cameraBlock.Mat4(cam.ProjectionMatrix())
+	cameraBlock.Mat4(cam.ViewMatrix())
+	cameraBlock.Mat4(cam.ViewProjection())
+	cameraBlock.Vec3(cam.CameraPos)
+	cameraBuffer.Update(&cameraBlock)
 
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	lightsBlock.Reset()
+	if err := scn.Lights(cam.CameraPos, cam.CameraFront).Encode(&lightsBlock); err != nil {
+		log.Fatal(err)
+	}
+	lightsBuffer.Update(&lightsBlock)
+
+	for _, object := range scn.Objects {
+		// without a material the model keeps its own
+		models[object.Mesh].Draw(object.Transform.Matrix(), materials.Get(object.Material))
 	}
 
 	//also draw the lamp object
 	lightCubeShader.Use()
-	lightCubeShader.SetMat4("projection", projection)
-	lightCubeShader.SetMat4("view", view)
 	lightCubeShader.SetVec3("color", mgl.Vec3{1.0, 1.0, 1.0})
 
-	for _, pointLight := range pointLightPositions {
+	for _, pointLight := range scn.PointLights {
 		model := mgl.Ident4()
-		model = model.Mul4(mgl.Translate3D(pointLight.X(), pointLight.Y(), pointLight.Z()))
+		model = model.Mul4(mgl.Translate3D(pointLight.Position.X(), pointLight.Position.Y(), pointLight.Position.Z()))
 		model = model.Mul4(mgl.Scale3D(0.3, 0.3, 0.3)) // a smaller cube
 		lightCubeShader.SetMat4("model", model)
 
-		gl.BindVertexArray(lightVao)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
+		lightCube.Draw()
+	}
+
+	// the skybox goes last, it only covers what's left of the background
+	// it's infinitely far away, which an orthographic projection can't show
+	if skybox != nil && cam.Projection == camera.Perspective {
+		skybox.Draw()
 	}
 
 	// Maintenance
@@ -235,65 +363,99 @@ func draw(vao uint32, lightVao uint32, window *glfw.Window, objectShader *graphi
 	glfw.PollEvents()
 }
 
-func getTextFileContents(filename string) string {
-	content, err := ioutil.ReadFile(filename)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Convert []byte to string
-	text := string(content)
-	return text
-}
-
-func processInput(window *glfw.Window) {
+func processInput(window *glfw.Window, models map[string]*graphics.Model) {
 	if window.GetKey(glfw.KeyEscape) == glfw.Press {
 		window.SetShouldClose(true)
 	}
 
 	if window.GetKey(glfw.KeyI) == glfw.Press {
 		log.Println("Information dump")
-		log.Println("Camera Position: ", camera.CameraPos)
-		log.Println("Camera Front: ", camera.CameraFront)
-		log.Println("Camera Up: ", camera.CameraUp)
+		log.Println("Camera Position: ", cam.CameraPos)
+		log.Println("Camera Front: ", cam.CameraFront)
+		log.Println("Camera Up: ", cam.CameraUp)
+	}
+
+	// switch between walking around and orbiting
+	if window.GetKey(glfw.KeyC) == glfw.Press {
+		if !switchHeld {
+			if controller == fpsController {
+				controller = orbitController
+			} else {
+				controller = fpsController
+			}
+			graphics.ActivateController(controller, &cam, window)
+		}
+		switchHeld = true
+	} else {
+		switchHeld = false
 	}
 
-	cameraSpeed := float32(2.5 * deltaTime)
-	// Forward
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.CameraPos = camera.CameraPos.Add(camera.CameraFront.Mul(cameraSpeed))
+	// switch between perspective and orthographic projection
+	if window.GetKey(glfw.KeyP) == glfw.Press {
+		if !projectionHeld {
+			if cam.Projection == camera.Perspective {
+				cam.Projection = camera.Orthographic
+			} else {
+				cam.Projection = camera.Perspective
+			}
+		}
+		projectionHeld = true
+	} else {
+		projectionHeld = false
 	}
 
-	// Backward
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.CameraPos = camera.CameraPos.Sub(camera.CameraFront.Mul(cameraSpeed))
+	// orbit around the whole scene
+	if window.GetKey(glfw.KeyF) == glfw.Press {
+		if controller != orbitController {
+			controller = orbitController
+			graphics.ActivateController(controller, &cam, window)
+		}
+		center, radius := sceneBounds(models)
+		orbitController.FrameSelected(&cam, center, radius)
 	}
 
-	// Left
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.CameraPos = camera.CameraPos.Sub(camera.CameraFront.Cross(camera.CameraUp).Normalize().Mul(cameraSpeed))
+	controller.Update(&cam, graphics.ReadInput(window), deltaTime)
+}
+
+// sceneBounds returns a sphere around every object of the scene, drawn with models.
+func sceneBounds(models map[string]*graphics.Model) (mgl.Vec3, float64) {
+	if len(scn.Objects) == 0 {
+		return mgl.Vec3{}, 1
 	}
 
-	// Right
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.CameraPos = camera.CameraPos.Add(camera.CameraFront.Cross(camera.CameraUp).Normalize().Mul(cameraSpeed))
+	// the corners of the boxes around the models of the objects
+	min := mgl.Vec3{float32(math.Inf(1)), float32(math.Inf(1)), float32(math.Inf(1))}
+	max := min.Mul(-1)
+	for _, object := range scn.Objects {
+		model := object.Transform.Matrix()
+		bounds := [2]mgl.Vec3{models[object.Mesh].Min, models[object.Mesh].Max}
+		for corner := 0; corner < 8; corner++ {
+			local := mgl.Vec3{bounds[corner&1][0], bounds[corner>>1&1][1], bounds[corner>>2&1][2]}
+			world := mgl.TransformCoordinate(local, model)
+			for i := 0; i < 3; i++ {
+				min[i] = float32(math.Min(float64(min[i]), float64(world[i])))
+				max[i] = float32(math.Max(float64(max[i]), float64(world[i])))
+			}
+		}
 	}
+	return min.Add(max).Mul(0.5), float64(max.Sub(min).Len() / 2)
 }
 
 // Run implements the main program loop of the demo. It returns when the platform signals to stop.
 // This demo application shows some basic features of ImGui, as well as exposing the standard demo window.
-func Run(p graphics.Platform, r graphics.Renderer, vao uint32, lightVao uint32, window *glfw.Window, objectShader *graphics.Shader, lightCubeShader *graphics.Shader, texture uint32, specularMap uint32) {
+func Run(p graphics.Platform, r graphics.Renderer, shaders *graphics.ShaderManager, models map[string]*graphics.Model, lightCube *graphics.Mesh, window *glfw.Window, lightCubeShader *graphics.Shader, materials *graphics.MaterialRegistry, skybox *graphics.Skybox) {
 	imgui.CurrentIO().SetClipboard(graphics.Clipboard{Platform: p})
 
 	showDemoWindow := false
 	showGoDemoWindow := false
-	clearColor := [3]float32{0.0, 0.0, 0.0}
+	clearColor := cnf.ClearColor
 	f := float32(0)
 	counter := 0
 	showAnotherWindow := false
 
 	for !p.ShouldStop() {
 		p.ProcessEvents()
+		shaders.Poll()
 
 		// Signal start of a new frame
 		p.NewFrame()
@@ -352,7 +514,7 @@ func Run(p graphics.Platform, r graphics.Renderer, vao uint32, lightVao uint32,
 
 		r.PreRender(clearColor)
 		// A this point, the application could perform its own rendering...
-		draw(vao, lightVao, window, objectShader, lightCubeShader, texture, specularMap)
+		draw(models, lightCube, window, lightCubeShader, materials, skybox)
 
 		r.Render(p.DisplaySize(), p.FramebufferSize(), imgui.RenderedDrawData())
 		p.PostRender()
@@ -0,0 +1,15 @@
+//go:build embed
+// +build embed
+
+package resources
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed scenes shaders textures
+var files embed.FS
+
+// FS holds the assets compiled into the binary.
+var FS fs.FS = files
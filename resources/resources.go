@@ -0,0 +1,11 @@
+//go:build !embed
+// +build !embed
+
+// Package resources can carry the assets in this directory inside the binary. Build with -tags embed
+// to have FS hold them; otherwise FS is nil and the assets are read from disk.
+package resources
+
+import "io/fs"
+
+// FS holds the assets compiled into the binary, or nil when they are read from disk.
+var FS fs.FS
@@ -0,0 +1,9 @@
+// The camera, shared by every program through a uniform buffer.
+layout (std140) uniform Camera
+{
+    mat4 projection;
+    mat4 view;
+    // projection * view
+    mat4 viewProjection;
+    vec3 viewPos;
+};
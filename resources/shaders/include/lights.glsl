@@ -0,0 +1,126 @@
+// The lights of the scene, shared by every program through a uniform buffer,
+// and the functions calculating how much light they add to a fragment.
+
+struct DirLight {
+    vec3 direction;
+
+    vec3 ambient;
+    vec3 diffuse;
+    vec3 specular;
+};
+
+struct PointLight {
+    vec3 position;
+
+    float constant;
+    float linear;
+    float quadratic;
+
+    vec3 ambient;
+    vec3 diffuse;
+    vec3 specular;
+};
+
+struct SpotLight {
+    vec3 position;
+    vec3 direction;
+    float cutOff;
+    float outerCutOff;
+
+    float constant;
+    float linear;
+    float quadratic;
+
+    vec3 ambient;
+    vec3 diffuse;
+    vec3 specular;
+};
+
+// The colours of the surface being lit, usually sampled from the material's textures.
+struct Surface {
+    vec3 diffuse;
+    vec3 specular;
+    float shininess;
+};
+
+// room for this many lights, nrPointLights and nrSpotLights say how many are in use.
+// The application defines both to match its own limits.
+#ifndef MAX_POINT_LIGHTS
+#define MAX_POINT_LIGHTS 32
+#endif
+#ifndef MAX_SPOT_LIGHTS
+#define MAX_SPOT_LIGHTS 32
+#endif
+
+layout (std140) uniform Lights
+{
+    DirLight dirLight;
+    PointLight pointLights[MAX_POINT_LIGHTS];
+    SpotLight spotLights[MAX_SPOT_LIGHTS];
+    int nrPointLights;
+    int nrSpotLights;
+};
+
+// calculates the color when using a directional light.
+vec3 CalcDirLight(DirLight light, Surface surface, vec3 normal, vec3 viewDir)
+{
+    vec3 lightDir = normalize(-light.direction);
+    // diffuse shading
+    float diff = max(dot(normal, lightDir), 0.0);
+    // specular shading
+    vec3 reflectDir = reflect(-lightDir, normal);
+    float spec = pow(max(dot(viewDir, reflectDir), 0.0), surface.shininess);
+    // combine results
+    vec3 ambient = light.ambient * surface.diffuse;
+    vec3 diffuse = light.diffuse * diff * surface.diffuse;
+    vec3 specular = light.specular * spec * surface.specular;
+    return (ambient + diffuse + specular);
+}
+
+// calculates the color when using a point light.
+vec3 CalcPointLight(PointLight light, Surface surface, vec3 normal, vec3 fragPos, vec3 viewDir)
+{
+    vec3 lightDir = normalize(light.position - fragPos);
+    // diffuse shading
+    float diff = max(dot(normal, lightDir), 0.0);
+    // specular shading
+    vec3 reflectDir = reflect(-lightDir, normal);
+    float spec = pow(max(dot(viewDir, reflectDir), 0.0), surface.shininess);
+    // attenuation
+    float distance = length(light.position - fragPos);
+    float attenuation = 1.0 / (light.constant + light.linear * distance + light.quadratic * (distance * distance));
+    // combine results
+    vec3 ambient = light.ambient * surface.diffuse;
+    vec3 diffuse = light.diffuse * diff * surface.diffuse;
+    vec3 specular = light.specular * spec * surface.specular;
+    ambient *= attenuation;
+    diffuse *= attenuation;
+    specular *= attenuation;
+    return (ambient + diffuse + specular);
+}
+
+// calculates the color when using a spot light.
+vec3 CalcSpotLight(SpotLight light, Surface surface, vec3 normal, vec3 fragPos, vec3 viewDir)
+{
+    vec3 lightDir = normalize(light.position - fragPos);
+    // diffuse shading
+    float diff = max(dot(normal, lightDir), 0.0);
+    // specular shading
+    vec3 reflectDir = reflect(-lightDir, normal);
+    float spec = pow(max(dot(viewDir, reflectDir), 0.0), surface.shininess);
+    // attenuation
+    float distance = length(light.position - fragPos);
+    float attenuation = 1.0 / (light.constant + light.linear * distance + light.quadratic * (distance * distance));
+    // spotlight intensity
+    float theta = dot(lightDir, normalize(-light.direction));
+    float epsilon = light.cutOff - light.outerCutOff;
+    float intensity = clamp((theta - light.outerCutOff) / epsilon, 0.0, 1.0);
+    // combine results
+    vec3 ambient = light.ambient * surface.diffuse;
+    vec3 diffuse = light.diffuse * diff * surface.diffuse;
+    vec3 specular = light.specular * spec * surface.specular;
+    ambient *= attenuation * intensity;
+    diffuse *= attenuation * intensity;
+    specular *= attenuation * intensity;
+    return (ambient + diffuse + specular);
+}
@@ -2,20 +2,32 @@
 layout (location = 0) in vec3 aPos;
 layout (location = 1) in vec3 aNormal;
 layout (location = 2) in vec2 aTexCoords;
+layout (location = 3) in vec4 aTangent;
 
 out vec3 FragPos;
 out vec3 Normal;
 out vec2 TexCoords;
+// tangent space to world space, for normal maps
+out mat3 TBN;
+
+#include "include/camera.glsl"
 
 uniform mat4 model;
-uniform mat4 view;
-uniform mat4 projection;
 
 void main()
 {
     FragPos = vec3(model * vec4(aPos, 1.0));
-    Normal = mat3(transpose(inverse(model))) * aNormal;
+    mat3 normalMatrix = mat3(transpose(inverse(model)));
+    Normal = normalMatrix * aNormal;
     TexCoords = aTexCoords;
 
-    gl_Position = projection * view * vec4(FragPos, 1.0);
+    vec3 N = normalize(Normal);
+    // re-orthogonalize, the tangent and normal drift apart under non-uniform scaling
+    vec3 T = normalize(mat3(model) * aTangent.xyz);
+    T = normalize(T - dot(T, N) * N);
+    // w flips the bitangent where the texture is mirrored
+    vec3 B = cross(N, T) * aTangent.w;
+    TBN = mat3(T, B, N);
+
+    gl_Position = viewProjection * vec4(FragPos, 1.0);
 }
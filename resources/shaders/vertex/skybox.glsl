@@ -0,0 +1,15 @@
+#version 330 core
+layout (location = 0) in vec3 aPos;
+
+#include "include/camera.glsl"
+
+out vec3 TexCoords;
+
+void main()
+{
+    TexCoords = aPos;
+    // drop the translation of the view, the box moves with the camera
+    vec4 pos = projection * mat4(mat3(view)) * vec4(aPos, 1.0);
+    // z = w puts the box on the far plane, behind everything else
+    gl_Position = pos.xyww;
+}
\ No newline at end of file
@@ -0,0 +1,204 @@
+package scene
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/PetrusJPrinsloo/learnopengl/asset"
+	"github.com/PetrusJPrinsloo/learnopengl/lighting"
+	"github.com/PetrusJPrinsloo/learnopengl/shape"
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// Scene is the in-memory form of a scene file. It lists the materials used by the scene,
+// the objects to draw and the lights illuminating them.
+type Scene struct {
+	Materials map[string]Material `json:"materials"`
+	Objects   []Object            `json:"objects"`
+
+	DirLight    lighting.DirLight    `json:"dirLight"`
+	PointLights lighting.PointLights `json:"pointLights"`
+	SpotLights  []SpotLight          `json:"spotLights"`
+
+	// Skyboxes are the environments the scene can be shown in, Skybox names the one that is drawn.
+	// Without one only the clear colour shows behind the objects.
+	Skyboxes map[string]Skybox `json:"skyboxes"`
+	Skybox   string            `json:"skybox"`
+}
+
+// Material describes the textures and surface parameters of an object. Normal is an optional tangent space normal map.
+type Material struct {
+	Diffuse   string  `json:"diffuse"`
+	Specular  string  `json:"specular"`
+	Normal    string  `json:"normal,omitempty"`
+	Shininess float32 `json:"shininess"`
+}
+
+// Skybox is a cube map drawn around the camera, made from six Faces or from an Equirect panorama.
+type Skybox struct {
+	// Faces are the images of the +X, -X, +Y, -Y, +Z and -Z faces, top row first.
+	Faces []string `json:"faces,omitempty"`
+	// Equirect is an equirectangular panorama, converted into faces of Size pixels.
+	Equirect string `json:"equirect,omitempty"`
+	Size     int    `json:"size,omitempty"`
+}
+
+// Object places a mesh in the world using one of the scene materials.
+type Object struct {
+	Name string `json:"name"`
+	// Mesh is "cube" or another of the shapes shape.Generated makes, or an .obj, .gltf or .glb model asset.
+	Mesh string `json:"mesh"`
+	// Material may be left out for models, which are then drawn with the materials of the model file.
+	Material  string    `json:"material,omitempty"`
+	Transform Transform `json:"transform"`
+}
+
+// Transform positions, rotates and scales an object. Rotation is in degrees around the X, Y and Z axes.
+type Transform struct {
+	Position mgl.Vec3 `json:"position"`
+	Rotation mgl.Vec3 `json:"rotation"`
+	Scale    mgl.Vec3 `json:"scale"`
+}
+
+// Matrix returns the model matrix of the transform, scaling first, then rotating and translating last.
+func (t Transform) Matrix() mgl.Mat4 {
+	rotation := mgl.AnglesToQuat(
+		mgl.DegToRad(t.Rotation.X()),
+		mgl.DegToRad(t.Rotation.Y()),
+		mgl.DegToRad(t.Rotation.Z()),
+		mgl.XYZ,
+	)
+
+	model := mgl.Translate3D(t.Position.X(), t.Position.Y(), t.Position.Z())
+	model = model.Mul4(rotation.Mat4())
+	return model.Mul4(mgl.Scale3D(t.Scale.X(), t.Scale.Y(), t.Scale.Z()))
+}
+
+// SpotLight is a spot light in the scene. A spot light attached to the camera follows the camera position
+// and direction, like a flashlight.
+type SpotLight struct {
+	lighting.SpotLight
+	AttachToCamera bool `json:"attachToCamera"`
+}
+
+// ReadFile loads and validates the scene asset called name.
+func ReadFile(name string) (*Scene, error) {
+	file, err := asset.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scn, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("scene %q: %w", name, err)
+	}
+	return scn, nil
+}
+
+// Read decodes a scene from r, fills in defaults for omitted values and validates it.
+func Read(r io.Reader) (*Scene, error) {
+	scn := &Scene{}
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(scn); err != nil {
+		return nil, err
+	}
+
+	scn.applyDefaults()
+	if err := scn.Validate(); err != nil {
+		return nil, err
+	}
+	return scn, nil
+}
+
+// Validate checks that every object names a mesh and refers to a material declared in the scene, unless it is
+// a model using its own materials,
+// that the skyboxes are complete and that the lights fit in the lighting shader.
+func (s *Scene) Validate() error {
+	for i, object := range s.Objects {
+		if object.Mesh == "" {
+			return fmt.Errorf("object %d (%q) has no mesh", i, object.Name)
+		}
+		if object.Material == "" && shape.IsModelFile(object.Mesh) {
+			continue
+		}
+		if _, ok := s.Materials[object.Material]; !ok {
+			return fmt.Errorf("object %d (%q) uses unknown material %q", i, object.Name, object.Material)
+		}
+	}
+
+	for name, skybox := range s.Skyboxes {
+		if (len(skybox.Faces) == 0) == (skybox.Equirect == "") {
+			return fmt.Errorf("skybox %q needs either faces or an equirect image", name)
+		}
+		if len(skybox.Faces) != 0 && len(skybox.Faces) != 6 {
+			return fmt.Errorf("skybox %q has %d faces instead of 6", name, len(skybox.Faces))
+		}
+		if skybox.Size < 0 {
+			return fmt.Errorf("skybox %q has a negative size", name)
+		}
+	}
+	if _, ok := s.Skyboxes[s.Skybox]; s.Skybox != "" && !ok {
+		return fmt.Errorf("unknown skybox %q", s.Skybox)
+	}
+
+	for i, light := range s.SpotLights {
+		if light.OuterCutOff < light.CutOff {
+			return fmt.Errorf("spot light %d has an outer cut-off smaller than its cut-off", i)
+		}
+	}
+
+	return s.Lights(mgl.Vec3{}, mgl.Vec3{}).Validate()
+}
+
+// Lights returns the lights of the scene, with the spot lights attached to the camera moved to
+// the camera position and pointed in the camera direction.
+func (s *Scene) Lights(cameraPos mgl.Vec3, cameraFront mgl.Vec3) lighting.Lights {
+	lights := lighting.Lights{
+		Dir:   s.DirLight,
+		Point: s.PointLights,
+		Spot:  make(lighting.SpotLights, len(s.SpotLights)),
+	}
+
+	for i, light := range s.SpotLights {
+		lights.Spot[i] = light.SpotLight
+		if light.AttachToCamera {
+			lights.Spot[i].Position = cameraPos
+			lights.Spot[i].Direction = cameraFront
+		}
+	}
+	return lights
+}
+
+// applyDefaults replaces values left out of the file whose zero value would make no sense.
+func (s *Scene) applyDefaults() {
+	for i := range s.Objects {
+		if s.Objects[i].Transform.Scale == (mgl.Vec3{}) {
+			s.Objects[i].Transform.Scale = mgl.Vec3{1, 1, 1}
+		}
+	}
+
+	for name, skybox := range s.Skyboxes {
+		if skybox.Equirect != "" && skybox.Size == 0 {
+			skybox.Size = 512
+			s.Skyboxes[name] = skybox
+		}
+	}
+
+	for i := range s.PointLights {
+		defaultAttenuation(&s.PointLights[i].Attenuation)
+	}
+	for i := range s.SpotLights {
+		defaultAttenuation(&s.SpotLights[i].Attenuation)
+	}
+}
+
+// defaultAttenuation makes a light without any attenuation terms keep its full strength at any distance.
+func defaultAttenuation(a *lighting.Attenuation) {
+	if a.Constant == 0 && a.Linear == 0 && a.Quadratic == 0 {
+		a.Constant = 1.0
+	}
+}
@@ -0,0 +1,393 @@
+package shape
+
+import (
+	"math"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// The generators build meshes in the TangentStride layout, centred on the origin with Y up and
+// wound counter-clockwise seen from outside. Round shapes go around the Y axis, sectors being the
+// number of slices around it.
+
+// generators make the meshes Generated knows, sized to fit the unit cube like Cube.
+var generators = map[string]func() MeshData{
+	"cube":      cube,
+	"plane":     func() MeshData { return Plane(1, 1, 1, 1) },
+	"sphere":    func() MeshData { return Sphere(0.5, 32, 16) },
+	"icosphere": func() MeshData { return Icosphere(0.5, 3) },
+	"cylinder":  func() MeshData { return Cylinder(0.5, 1, 32) },
+	"cone":      func() MeshData { return Cone(0.5, 1, 32) },
+	"torus":     func() MeshData { return Torus(0.35, 0.15, 32, 16) },
+	"capsule":   func() MeshData { return Capsule(0.25, 0.5, 32, 8) },
+}
+
+// Generated returns the mesh called name, one of "cube", "plane", "sphere", "icosphere", "cylinder", "cone",
+// "torus" and "capsule", sized to fit the unit cube around the origin. ok is false for any other name.
+func Generated(name string) (mesh MeshData, ok bool) {
+	generate, ok := generators[name]
+	if !ok {
+		return MeshData{}, false
+	}
+	return generate(), true
+}
+
+// cube returns Cube indexed and with tangents. Some of its faces are wound clockwise, which goes unnoticed without
+// face culling, they are turned around to face the way of their normals like the other generated meshes.
+func cube() MeshData {
+	vertices, indices := Index(Cube, VertexStride)
+	vector := func(index uint32, offset int) mgl.Vec3 {
+		v := vertices[int(index)*VertexStride+offset:]
+		return mgl.Vec3{v[0], v[1], v[2]}
+	}
+	for i := 0; i < len(indices); i += 3 {
+		p0, p1, p2 := vector(indices[i], 0), vector(indices[i+1], 0), vector(indices[i+2], 0)
+		if p1.Sub(p0).Cross(p2.Sub(p0)).Dot(vector(indices[i], 3)) < 0 {
+			indices[i+1], indices[i+2] = indices[i+2], indices[i+1]
+		}
+	}
+	return GenerateTangents(MeshData{Name: "cube", Vertices: vertices, Indices: indices, Stride: VertexStride})
+}
+
+// Plane returns a width by depth plane in the XZ plane facing up, split into segmentsX by segmentsZ quads.
+func Plane(width, depth float32, segmentsX, segmentsZ int) MeshData {
+	segmentsX, segmentsZ = atLeast(segmentsX, 1), atLeast(segmentsZ, 1)
+
+	var b meshBuilder
+	for row := 0; row <= segmentsZ; row++ {
+		v := float32(row) / float32(segmentsZ)
+		for column := 0; column <= segmentsX; column++ {
+			u := float32(column) / float32(segmentsX)
+			// v runs towards -Z so the texture reads the right way round seen from above
+			b.vertex(mgl.Vec3{(u - 0.5) * width, 0, (0.5 - v) * depth}, mgl.Vec3{0, 1, 0}, mgl.Vec2{u, v}, mgl.Vec3{1, 0, 0})
+		}
+	}
+	b.grid(0, segmentsX, segmentsZ)
+	return b.mesh("plane")
+}
+
+// Sphere returns a UV sphere made of stacks rings from pole to pole.
+func Sphere(radius float32, sectors, stacks int) MeshData {
+	sectors, stacks = atLeast(sectors, 3), atLeast(stacks, 2)
+
+	rings := make([]ring, stacks+1)
+	for i := range rings {
+		theta := math.Pi * float64(i) / float64(stacks)
+		rings[i] = latitude(radius, theta-math.Pi/2, 0, float32(i)/float32(stacks))
+	}
+
+	var b meshBuilder
+	b.revolve(rings, sectors)
+	return b.mesh("sphere")
+}
+
+// Icosphere returns a sphere made by subdividing an icosahedron, with triangles of nearly equal size.
+// Every subdivision splits each triangle into four.
+func Icosphere(radius float32, subdivisions int) MeshData {
+	t := float32((1 + math.Sqrt(5)) / 2)
+	corners := []mgl.Vec3{
+		{-1, t, 0}, {1, t, 0}, {-1, -t, 0}, {1, -t, 0},
+		{0, -1, t}, {0, 1, t}, {0, -1, -t}, {0, 1, -t},
+		{t, 0, -1}, {t, 0, 1}, {-t, 0, -1}, {-t, 0, 1},
+	}
+	faces := [][3]int{
+		{0, 11, 5}, {0, 5, 1}, {0, 1, 7}, {0, 7, 10}, {0, 10, 11},
+		{1, 5, 9}, {5, 11, 4}, {11, 10, 2}, {10, 7, 6}, {7, 1, 8},
+		{3, 9, 4}, {3, 4, 2}, {3, 2, 6}, {3, 6, 8}, {3, 8, 9},
+		{4, 9, 5}, {2, 4, 11}, {6, 2, 10}, {8, 6, 7}, {9, 8, 1},
+	}
+
+	triangles := make([][3]mgl.Vec3, len(faces))
+	for i, face := range faces {
+		triangles[i] = [3]mgl.Vec3{corners[face[0]].Normalize(), corners[face[1]].Normalize(), corners[face[2]].Normalize()}
+	}
+	for i := 0; i < subdivisions; i++ {
+		triangles = subdivide(triangles)
+	}
+
+	var b meshBuilder
+	for _, triangle := range triangles {
+		var uvs [3]mgl.Vec2
+		pole := -1
+		for i, direction := range triangle {
+			uvs[i] = sphereUV(direction)
+			if abs(direction[1]) > 0.99999 {
+				pole = i
+			}
+		}
+		// a triangle crossing the seam at u = 0 would stretch over the whole texture, give it its own vertices past u = 1
+		others := make([]int, 0, 3)
+		for i := range uvs {
+			if i != pole {
+				others = append(others, i)
+			}
+		}
+		if seam(uvs, others) {
+			for _, i := range others {
+				if uvs[i][0] < 0.5 {
+					uvs[i][0]++
+				}
+			}
+		}
+		// u means nothing at a pole, take the one in the middle of the other corners
+		if pole >= 0 {
+			uvs[pole][0] = (uvs[others[0]][0] + uvs[others[1]][0]) / 2
+		}
+
+		var indices [3]uint32
+		for i, direction := range triangle {
+			indices[i] = b.vertex(direction.Mul(radius), direction, uvs[i], aroundY(uvs[i][0]))
+		}
+		b.triangle(indices[0], indices[1], indices[2])
+	}
+
+	// the triangles were emitted separately, share the vertices they have in common
+	mesh := b.mesh("icosphere")
+	mesh.Vertices, mesh.Indices = reindex(mesh.Vertices, mesh.Indices, TangentStride)
+	return mesh
+}
+
+// Cylinder returns a closed cylinder of the given radius and height.
+func Cylinder(radius, height float32, sectors int) MeshData {
+	sectors = atLeast(sectors, 3)
+
+	var b meshBuilder
+	b.revolve([]ring{
+		{radius: radius, y: -height / 2, normalXZ: 1, v: 0},
+		{radius: radius, y: height / 2, normalXZ: 1, v: 1},
+	}, sectors)
+	b.disc(radius, -height/2, sectors, false)
+	b.disc(radius, height/2, sectors, true)
+	return b.mesh("cylinder")
+}
+
+// Cone returns a cone with its base of the given radius at the bottom and its tip at the top.
+func Cone(radius, height float32, sectors int) MeshData {
+	sectors = atLeast(sectors, 3)
+
+	// the normal of the side leans up by the angle of the slope
+	slope := mgl.Vec2{height, radius}.Normalize()
+
+	var b meshBuilder
+	b.revolve([]ring{
+		{radius: radius, y: -height / 2, normalXZ: slope[0], normalY: slope[1], v: 0},
+		{radius: 0, y: height / 2, normalXZ: slope[0], normalY: slope[1], v: 1},
+	}, sectors)
+	b.disc(radius, -height/2, sectors, false)
+	return b.mesh("cone")
+}
+
+// Torus returns a ring around the Y axis. majorRadius is the distance from the centre to the middle of the tube,
+// minorRadius the radius of the tube.
+func Torus(majorRadius, minorRadius float32, majorSegments, minorSegments int) MeshData {
+	majorSegments, minorSegments = atLeast(majorSegments, 3), atLeast(minorSegments, 3)
+
+	rings := make([]ring, minorSegments+1)
+	for i := range rings {
+		theta := 2 * math.Pi * float64(i) / float64(minorSegments)
+		cos, sin := float32(math.Cos(theta)), float32(math.Sin(theta))
+		rings[i] = ring{
+			radius:   majorRadius + minorRadius*cos,
+			y:        minorRadius * sin,
+			normalXZ: cos,
+			normalY:  sin,
+			v:        float32(i) / float32(minorSegments),
+		}
+	}
+
+	var b meshBuilder
+	b.revolve(rings, majorSegments)
+	return b.mesh("torus")
+}
+
+// Capsule returns a cylinder of the given height capped with half spheres, so it is height + 2*radius tall.
+// stacks is the number of rings in each half sphere.
+func Capsule(radius, height float32, sectors, stacks int) MeshData {
+	sectors, stacks = atLeast(sectors, 3), atLeast(stacks, 1)
+
+	total := height + 2*radius
+	var rings []ring
+	for _, half := range []struct{ from, y float64 }{{-math.Pi / 2, float64(-height / 2)}, {0, float64(height / 2)}} {
+		for i := 0; i <= stacks; i++ {
+			angle := half.from + math.Pi/2*float64(i)/float64(stacks)
+			r := latitude(radius, angle, float32(half.y), 0)
+			r.v = (r.y + total/2) / total
+			rings = append(rings, r)
+		}
+	}
+
+	var b meshBuilder
+	b.revolve(rings, sectors)
+	return b.mesh("capsule")
+}
+
+// ring is a circle around the Y axis on a surface of revolution. The normal of the surface along the ring has a
+// horizontal part pointing away from the axis and a vertical part.
+type ring struct {
+	radius, y         float32
+	normalXZ, normalY float32
+	v                 float32
+}
+
+// latitude is the ring of a sphere at angle from the equator, moved up by y.
+func latitude(radius float32, angle float64, y float32, v float32) ring {
+	cos, sin := float32(math.Cos(angle)), float32(math.Sin(angle))
+	return ring{radius: radius * cos, y: y + radius*sin, normalXZ: cos, normalY: sin, v: v}
+}
+
+type meshBuilder struct {
+	vertices []float32
+	indices  []uint32
+}
+
+// vertex adds a vertex with a tangent of positive handedness and returns its index.
+func (b *meshBuilder) vertex(position, normal mgl.Vec3, uv mgl.Vec2, tangent mgl.Vec3) uint32 {
+	index := uint32(len(b.vertices) / TangentStride)
+	b.vertices = append(b.vertices,
+		position[0], position[1], position[2],
+		normal[0], normal[1], normal[2],
+		uv[0], uv[1],
+		tangent[0], tangent[1], tangent[2], 1,
+	)
+	return index
+}
+
+func (b *meshBuilder) position(index uint32) mgl.Vec3 {
+	v := b.vertices[int(index)*TangentStride:]
+	return mgl.Vec3{v[0], v[1], v[2]}
+}
+
+// triangle adds a triangle, leaving out the degenerate ones at the poles and tips.
+func (b *meshBuilder) triangle(i0, i1, i2 uint32) {
+	p0, p1, p2 := b.position(i0), b.position(i1), b.position(i2)
+	if p1.Sub(p0).Cross(p2.Sub(p0)).Len() < 1e-9 {
+		return
+	}
+	b.indices = append(b.indices, i0, i1, i2)
+}
+
+// grid adds the quads between rows+1 rows of columns+1 vertices starting at first. The rows have to run along
+// the tangent and follow each other along the bitangent for the quads to face the way of the normal.
+func (b *meshBuilder) grid(first uint32, columns, rows int) {
+	width := uint32(columns + 1)
+	for row := uint32(0); row < uint32(rows); row++ {
+		for column := uint32(0); column < uint32(columns); column++ {
+			bottomLeft := first + row*width + column
+			topLeft := bottomLeft + width
+			b.triangle(bottomLeft, bottomLeft+1, topLeft+1)
+			b.triangle(bottomLeft, topLeft+1, topLeft)
+		}
+	}
+}
+
+// revolve sweeps rings around the Y axis. The first and last vertex of a ring sit on the same spot with
+// u = 0 and u = 1, so the texture wraps around once.
+func (b *meshBuilder) revolve(rings []ring, sectors int) {
+	first := uint32(len(b.vertices) / TangentStride)
+	for _, r := range rings {
+		for sector := 0; sector <= sectors; sector++ {
+			u := float32(sector) / float32(sectors)
+			phi := 2 * math.Pi * float64(u)
+			cos, sin := float32(math.Cos(phi)), float32(math.Sin(phi))
+
+			// phi turns counter-clockwise seen from above, from +X towards -Z
+			position := mgl.Vec3{r.radius * cos, r.y, -r.radius * sin}
+			normal := mgl.Vec3{r.normalXZ * cos, r.normalY, -r.normalXZ * sin}
+			b.vertex(position, normal, mgl.Vec2{u, r.v}, aroundY(u))
+		}
+	}
+	b.grid(first, sectors, len(rings)-1)
+}
+
+// disc adds a flat cap at height y, facing up or down.
+func (b *meshBuilder) disc(radius, y float32, sectors int, up bool) {
+	normal := mgl.Vec3{0, -1, 0}
+	if up {
+		normal = mgl.Vec3{0, 1, 0}
+	}
+
+	// seen from outside the texture runs along +X, and along -Z on top or +Z below
+	vDirection := normal[1]
+	centre := b.vertex(mgl.Vec3{0, y, 0}, normal, mgl.Vec2{0.5, 0.5}, mgl.Vec3{1, 0, 0})
+	for sector := 0; sector <= sectors; sector++ {
+		phi := 2 * math.Pi * float64(sector) / float64(sectors)
+		cos, sin := float32(math.Cos(phi)), float32(math.Sin(phi))
+		uv := mgl.Vec2{0.5 + 0.5*cos, 0.5 + 0.5*sin*vDirection}
+		b.vertex(mgl.Vec3{radius * cos, y, -radius * sin}, normal, uv, mgl.Vec3{1, 0, 0})
+	}
+
+	for sector := uint32(1); sector <= uint32(sectors); sector++ {
+		if up {
+			b.triangle(centre, centre+sector, centre+sector+1)
+		} else {
+			b.triangle(centre, centre+sector+1, centre+sector)
+		}
+	}
+}
+
+func (b *meshBuilder) mesh(name string) MeshData {
+	return MeshData{Name: name, Vertices: b.vertices, Indices: b.indices, Stride: TangentStride}
+}
+
+// subdivide splits every triangle into four, pushing the new corners out onto the unit sphere.
+func subdivide(triangles [][3]mgl.Vec3) [][3]mgl.Vec3 {
+	divided := make([][3]mgl.Vec3, 0, len(triangles)*4)
+	for _, t := range triangles {
+		a := t[0].Add(t[1]).Normalize()
+		b := t[1].Add(t[2]).Normalize()
+		c := t[2].Add(t[0]).Normalize()
+		divided = append(divided,
+			[3]mgl.Vec3{t[0], a, c},
+			[3]mgl.Vec3{t[1], b, a},
+			[3]mgl.Vec3{t[2], c, b},
+			[3]mgl.Vec3{a, b, c},
+		)
+	}
+	return divided
+}
+
+// sphereUV maps a direction to the texture coordinates Sphere uses for it.
+func sphereUV(direction mgl.Vec3) mgl.Vec2 {
+	u := math.Atan2(float64(-direction[2]), float64(direction[0])) / (2 * math.Pi)
+	if u < 0 {
+		u++
+	}
+	v := 0.5 + math.Asin(float64(mgl.Clamp(direction[1], -1, 1)))/math.Pi
+	return mgl.Vec2{float32(u), float32(v)}
+}
+
+// aroundY is the tangent of a surface of revolution at texture coordinate u, pointing the way u grows.
+func aroundY(u float32) mgl.Vec3 {
+	phi := 2 * math.Pi * float64(u)
+	return mgl.Vec3{float32(-math.Sin(phi)), 0, float32(-math.Cos(phi))}
+}
+
+// reindex merges identical vertices of an indexed mesh.
+func reindex(vertices []float32, indices []uint32, stride int) ([]float32, []uint32) {
+	expanded := make([]float32, 0, len(indices)*stride)
+	for _, index := range indices {
+		expanded = append(expanded, vertices[int(index)*stride:int(index+1)*stride]...)
+	}
+	return Index(expanded, stride)
+}
+
+// seam tells whether the corners of a triangle lie on both sides of u = 0.
+func seam(uvs [3]mgl.Vec2, corners []int) bool {
+	min, max := float32(1), float32(0)
+	for _, i := range corners {
+		if uvs[i][0] < min {
+			min = uvs[i][0]
+		}
+		if uvs[i][0] > max {
+			max = uvs[i][0]
+		}
+	}
+	return max-min > 0.5
+}
+
+func atLeast(n, min int) int {
+	if n < min {
+		return min
+	}
+	return n
+}
@@ -0,0 +1,89 @@
+package shape
+
+import (
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+var generatedMeshes = []struct {
+	name string
+	mesh MeshData
+}{
+	{"plane", Plane(2, 3, 4, 5)},
+	{"plane with one segment", Plane(1, 1, 0, 0)},
+	{"sphere", Sphere(1, 16, 8)},
+	{"sphere with few sectors", Sphere(0.5, 3, 2)},
+	{"icosphere", Icosphere(1, 0)},
+	{"subdivided icosphere", Icosphere(2, 3)},
+	{"cylinder", Cylinder(0.5, 2, 12)},
+	{"cone", Cone(1, 2, 12)},
+	{"torus", Torus(1, 0.25, 24, 12)},
+	{"capsule", Capsule(0.5, 1, 16, 4)},
+	{"capsule without a middle", Capsule(0.5, 0, 16, 4)},
+	{"cube", cube()},
+}
+
+func vec3At(mesh MeshData, index uint32, offset int) mgl.Vec3 {
+	v := mesh.Vertices[int(index)*mesh.Stride+offset:]
+	return mgl.Vec3{v[0], v[1], v[2]}
+}
+
+func TestGeneratedVectorsAreUnitLength(t *testing.T) {
+	for _, test := range generatedMeshes {
+		t.Run(test.name, func(t *testing.T) {
+			mesh := test.mesh
+			if mesh.Stride != TangentStride || len(mesh.Vertices)%mesh.Stride != 0 {
+				t.Fatalf("%d floats with stride %d", len(mesh.Vertices), mesh.Stride)
+			}
+			for i := uint32(0); int(i) < len(mesh.Vertices)/mesh.Stride; i++ {
+				normal, tangent := vec3At(mesh, i, 3), vec3At(mesh, i, 8)
+				if !mgl.FloatEqualThreshold(normal.Len(), 1, 1e-4) {
+					t.Fatalf("vertex %d: normal %v has length %g", i, normal, normal.Len())
+				}
+				if !mgl.FloatEqualThreshold(tangent.Len(), 1, 1e-4) {
+					t.Fatalf("vertex %d: tangent %v has length %g", i, tangent, tangent.Len())
+				}
+				if dot := normal.Dot(tangent); abs(dot) > 1e-4 {
+					t.Fatalf("vertex %d: tangent %v isn't perpendicular to the normal %v", i, tangent, normal)
+				}
+				if w := mesh.Vertices[int(i)*mesh.Stride+11]; w != 1 && w != -1 {
+					t.Fatalf("vertex %d: handedness %g", i, w)
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratedWinding(t *testing.T) {
+	for _, test := range generatedMeshes {
+		t.Run(test.name, func(t *testing.T) {
+			mesh := test.mesh
+			vertexCount := uint32(len(mesh.Vertices) / mesh.Stride)
+			if len(mesh.Indices) == 0 || len(mesh.Indices)%3 != 0 {
+				t.Fatalf("%d indices", len(mesh.Indices))
+			}
+			for _, index := range mesh.Indices {
+				if index >= vertexCount {
+					t.Fatalf("index %d out of range, there are %d vertices", index, vertexCount)
+				}
+			}
+
+			// counter-clockwise triangles face the way their vertex normals point
+			for i := 0; i < len(mesh.Indices); i += 3 {
+				corners := mesh.Indices[i : i+3]
+				p0, p1, p2 := vec3At(mesh, corners[0], 0), vec3At(mesh, corners[1], 0), vec3At(mesh, corners[2], 0)
+				faceNormal := p1.Sub(p0).Cross(p2.Sub(p0))
+				if faceNormal.Len() == 0 {
+					t.Fatalf("triangle %d is degenerate", i/3)
+				}
+				faceNormal = faceNormal.Normalize()
+				for _, corner := range corners {
+					if faceNormal.Dot(vec3At(mesh, corner, 3)) <= 0 {
+						t.Fatalf("triangle %d faces %v, away from the normal %v of vertex %d", i/3, faceNormal, vec3At(mesh, corner, 3), corner)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,834 @@
+package shape
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/PetrusJPrinsloo/learnopengl/asset"
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// LoadGLTF loads the glTF 2.0 asset called name, either a .gltf JSON file or a binary .glb file.
+func LoadGLTF(name string) (*Model, error) {
+	data, err := asset.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return ReadGLTF(data, name, asset.ReadFile)
+}
+
+// ReadGLTF parses a glTF 2.0 file called name, in JSON or binary (.glb) form. External buffers and images are
+// resolved relative to name; readFile reads the buffers.
+//
+// Every primitive of a glTF mesh becomes a MeshData in the VertexStride layout, or the TangentStride layout if it
+// has tangents, with texture coordinates flipped to put the origin at the bottom like Cube. Images stored in the file end up in Model.Images,
+// external images are referred to by their asset name.
+func ReadGLTF(data []byte, name string, readFile func(name string) ([]byte, error)) (*Model, error) {
+	l := &gltfLoader{name: name, dir: path.Dir(name), readFile: readFile}
+
+	jsonData := data
+	if bytes.HasPrefix(data, []byte("glTF")) {
+		var err error
+		jsonData, l.binary, err = splitGLB(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+
+	if err := json.Unmarshal(jsonData, &l.doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if !strings.HasPrefix(l.doc.Asset.Version, "2.") {
+		return nil, fmt.Errorf("%s: unsupported glTF version %q", name, l.doc.Asset.Version)
+	}
+
+	model, err := l.model()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return model, nil
+}
+
+// The parts of the glTF 2.0 schema the loader uses.
+type gltfDocument struct {
+	Asset struct {
+		Version string `json:"version"`
+	} `json:"asset"`
+	Scene       *int             `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes"`
+	Meshes      []gltfMesh       `json:"meshes"`
+	Accessors   []gltfAccessor   `json:"accessors"`
+	BufferViews []gltfBufferView `json:"bufferViews"`
+	Buffers     []gltfBuffer     `json:"buffers"`
+	Materials   []gltfMaterial   `json:"materials"`
+	Textures    []gltfTexture    `json:"textures"`
+	Images      []gltfImage      `json:"images"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name        string    `json:"name"`
+	Mesh        *int      `json:"mesh"`
+	Children    []int     `json:"children"`
+	Matrix      []float32 `json:"matrix"`
+	Translation []float32 `json:"translation"`
+	Rotation    []float32 `json:"rotation"`
+	Scale       []float32 `json:"scale"`
+}
+
+type gltfMesh struct {
+	Name       string          `json:"name"`
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices"`
+	Material   *int           `json:"material"`
+	Mode       *int           `json:"mode"`
+}
+
+type gltfAccessor struct {
+	BufferView    *int            `json:"bufferView"`
+	ByteOffset    int             `json:"byteOffset"`
+	ComponentType int             `json:"componentType"`
+	Normalized    bool            `json:"normalized"`
+	Count         int             `json:"count"`
+	Type          string          `json:"type"`
+	Sparse        json.RawMessage `json:"sparse"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride"`
+}
+
+type gltfBuffer struct {
+	URI        string `json:"uri"`
+	ByteLength int    `json:"byteLength"`
+}
+
+type gltfMaterial struct {
+	Name                 string `json:"name"`
+	PBRMetallicRoughness struct {
+		BaseColorFactor          []float32        `json:"baseColorFactor"`
+		BaseColorTexture         *gltfTextureInfo `json:"baseColorTexture"`
+		MetallicFactor           *float32         `json:"metallicFactor"`
+		RoughnessFactor          *float32         `json:"roughnessFactor"`
+		MetallicRoughnessTexture *gltfTextureInfo `json:"metallicRoughnessTexture"`
+	} `json:"pbrMetallicRoughness"`
+	NormalTexture *gltfTextureInfo `json:"normalTexture"`
+}
+
+type gltfTextureInfo struct {
+	Index int `json:"index"`
+}
+
+type gltfTexture struct {
+	Source *int `json:"source"`
+}
+
+type gltfImage struct {
+	URI        string `json:"uri"`
+	BufferView *int   `json:"bufferView"`
+	MimeType   string `json:"mimeType"`
+}
+
+// Component types and primitive modes from the glTF specification, they are the GL enums.
+const (
+	gltfByte          = 5120
+	gltfUnsignedByte  = 5121
+	gltfShort         = 5122
+	gltfUnsignedShort = 5123
+	gltfUnsignedInt   = 5125
+	gltfFloat         = 5126
+
+	gltfTriangles     = 4
+	gltfTriangleStrip = 5
+	gltfTriangleFan   = 6
+)
+
+// maxByteStride is the largest byteStride the glTF specification allows.
+const maxByteStride = 252
+
+// maxZeroAccessorCount limits the accessors without a bufferView, which the loader fills with zeros.
+// Their size isn't bounded by any data in the file.
+const maxZeroAccessorCount = 1 << 24
+
+var gltfComponents = map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4, "MAT2": 4, "MAT3": 9, "MAT4": 16}
+
+type gltfLoader struct {
+	name     string
+	dir      string
+	readFile func(name string) ([]byte, error)
+
+	doc     gltfDocument
+	binary  []byte
+	buffers [][]byte
+}
+
+// splitGLB returns the JSON and the binary chunk of a .glb file.
+func splitGLB(data []byte) ([]byte, []byte, error) {
+	if len(data) < 20 {
+		return nil, nil, fmt.Errorf("truncated glb header")
+	}
+	if version := binary.LittleEndian.Uint32(data[4:]); version != 2 {
+		return nil, nil, fmt.Errorf("unsupported glb version %d", version)
+	}
+	if length := binary.LittleEndian.Uint32(data[8:]); int(length) > len(data) {
+		return nil, nil, fmt.Errorf("glb is %d bytes, the header says %d", len(data), length)
+	}
+
+	var jsonChunk, binChunk []byte
+	for offset := 12; offset+8 <= len(data); {
+		length := int(binary.LittleEndian.Uint32(data[offset:]))
+		chunkType := binary.LittleEndian.Uint32(data[offset+4:])
+		start := offset + 8
+		if start+length > len(data) {
+			return nil, nil, fmt.Errorf("truncated glb chunk")
+		}
+
+		switch chunkType {
+		case 0x4E4F534A: // "JSON"
+			jsonChunk = data[start : start+length]
+		case 0x004E4942: // "BIN\x00"
+			if binChunk == nil {
+				binChunk = data[start : start+length]
+			}
+		}
+		offset = start + length
+	}
+
+	if jsonChunk == nil {
+		return nil, nil, fmt.Errorf("glb without a JSON chunk")
+	}
+	return jsonChunk, binChunk, nil
+}
+
+func (l *gltfLoader) model() (*Model, error) {
+	if err := l.loadBuffers(); err != nil {
+		return nil, err
+	}
+
+	model := &Model{Materials: map[string]Material{}, Images: map[string][]byte{}}
+
+	materialNames, err := l.materials(model)
+	if err != nil {
+		return nil, err
+	}
+
+	// the meshes of the model are the primitives, remember which belong to every glTF mesh
+	primitives := make([][]int, len(l.doc.Meshes))
+	for i, mesh := range l.doc.Meshes {
+		for j, primitive := range mesh.Primitives {
+			data, err := l.primitive(primitive)
+			if err != nil {
+				return nil, fmt.Errorf("mesh %d primitive %d: %v", i, j, err)
+			}
+			data.Name = mesh.Name
+			if primitive.Material != nil {
+				if *primitive.Material < 0 || *primitive.Material >= len(materialNames) {
+					return nil, fmt.Errorf("mesh %d primitive %d: material %d out of range", i, j, *primitive.Material)
+				}
+				data.Material = materialNames[*primitive.Material]
+			}
+			primitives[i] = append(primitives[i], len(model.Meshes))
+			model.Meshes = append(model.Meshes, data)
+		}
+	}
+
+	for i, node := range l.doc.Nodes {
+		transform, err := node.transform()
+		if err != nil {
+			return nil, fmt.Errorf("node %d: %v", i, err)
+		}
+		modelNode := Node{Name: node.Name, Transform: transform, Children: node.Children}
+		if node.Mesh != nil {
+			if *node.Mesh < 0 || *node.Mesh >= len(primitives) {
+				return nil, fmt.Errorf("node %d: mesh %d out of range", i, *node.Mesh)
+			}
+			modelNode.Meshes = primitives[*node.Mesh]
+		}
+		for _, child := range node.Children {
+			if child < 0 || child >= len(l.doc.Nodes) {
+				return nil, fmt.Errorf("node %d: child %d out of range", i, child)
+			}
+		}
+		model.Nodes = append(model.Nodes, modelNode)
+	}
+
+	if err := checkHierarchy(model.Nodes); err != nil {
+		return nil, err
+	}
+
+	model.Roots, err = l.roots()
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// checkHierarchy makes sure the nodes form trees: a node has at most one parent and isn't its own ancestor,
+// which would send WorldTransforms round in circles.
+func checkHierarchy(nodes []Node) error {
+	parents := make([]int, len(nodes))
+	for i := range parents {
+		parents[i] = -1
+	}
+	for i, node := range nodes {
+		for _, child := range node.Children {
+			if parents[child] >= 0 {
+				return fmt.Errorf("node %d is a child of both node %d and node %d", child, parents[child], i)
+			}
+			parents[child] = i
+		}
+	}
+
+	// walk up from every node, a walk running into its own path has gone round a cycle
+	const unvisited, onPath, checked = 0, 1, 2
+	state := make([]int, len(nodes))
+	for i := range nodes {
+		node := i
+		for node >= 0 && state[node] == unvisited {
+			state[node] = onPath
+			node = parents[node]
+		}
+		if node >= 0 && state[node] == onPath {
+			return fmt.Errorf("node %d is its own ancestor", node)
+		}
+		for node := i; node >= 0 && state[node] == onPath; node = parents[node] {
+			state[node] = checked
+		}
+	}
+	return nil
+}
+
+// roots returns the nodes of the default scene, or every node without a parent if there are no scenes.
+func (l *gltfLoader) roots() ([]int, error) {
+	if len(l.doc.Scenes) > 0 {
+		scene := 0
+		if l.doc.Scene != nil {
+			scene = *l.doc.Scene
+		}
+		if scene < 0 || scene >= len(l.doc.Scenes) {
+			return nil, fmt.Errorf("scene %d out of range", scene)
+		}
+		for _, node := range l.doc.Scenes[scene].Nodes {
+			if node < 0 || node >= len(l.doc.Nodes) {
+				return nil, fmt.Errorf("scene %d: node %d out of range", scene, node)
+			}
+		}
+		return l.doc.Scenes[scene].Nodes, nil
+	}
+
+	child := make([]bool, len(l.doc.Nodes))
+	for _, node := range l.doc.Nodes {
+		for _, c := range node.Children {
+			child[c] = true
+		}
+	}
+	var roots []int
+	for i := range l.doc.Nodes {
+		if !child[i] {
+			roots = append(roots, i)
+		}
+	}
+	return roots, nil
+}
+
+func (n *gltfNode) transform() (mgl.Mat4, error) {
+	if n.Matrix != nil {
+		if len(n.Matrix) != 16 {
+			return mgl.Mat4{}, fmt.Errorf("matrix has %d elements", len(n.Matrix))
+		}
+		// both glTF and mgl store matrices column by column
+		var m mgl.Mat4
+		copy(m[:], n.Matrix)
+		return m, nil
+	}
+
+	translation := mgl.Ident4()
+	if n.Translation != nil {
+		if len(n.Translation) != 3 {
+			return mgl.Mat4{}, fmt.Errorf("translation has %d elements", len(n.Translation))
+		}
+		translation = mgl.Translate3D(n.Translation[0], n.Translation[1], n.Translation[2])
+	}
+
+	rotation := mgl.Ident4()
+	if n.Rotation != nil {
+		if len(n.Rotation) != 4 {
+			return mgl.Mat4{}, fmt.Errorf("rotation has %d elements", len(n.Rotation))
+		}
+		// glTF stores quaternions as x, y, z, w
+		q := mgl.Quat{W: n.Rotation[3], V: mgl.Vec3{n.Rotation[0], n.Rotation[1], n.Rotation[2]}}
+		rotation = q.Normalize().Mat4()
+	}
+
+	scale := mgl.Ident4()
+	if n.Scale != nil {
+		if len(n.Scale) != 3 {
+			return mgl.Mat4{}, fmt.Errorf("scale has %d elements", len(n.Scale))
+		}
+		scale = mgl.Scale3D(n.Scale[0], n.Scale[1], n.Scale[2])
+	}
+
+	return translation.Mul4(rotation).Mul4(scale), nil
+}
+
+func (l *gltfLoader) loadBuffers() error {
+	for i, buffer := range l.doc.Buffers {
+		var data []byte
+		switch {
+		case buffer.URI == "":
+			// the buffer of a .glb file is its binary chunk
+			if i != 0 || l.binary == nil {
+				return fmt.Errorf("buffer %d has no uri", i)
+			}
+			data = l.binary
+		case strings.HasPrefix(buffer.URI, "data:"):
+			var err error
+			if data, err = decodeDataURI(buffer.URI); err != nil {
+				return fmt.Errorf("buffer %d: %v", i, err)
+			}
+		default:
+			file, err := l.uriAsset(buffer.URI)
+			if err != nil {
+				return fmt.Errorf("buffer %d: %v", i, err)
+			}
+			if data, err = l.readFile(file); err != nil {
+				return err
+			}
+		}
+
+		if len(data) < buffer.ByteLength {
+			return fmt.Errorf("buffer %d has %d bytes, expected %d", i, len(data), buffer.ByteLength)
+		}
+		l.buffers = append(l.buffers, data)
+	}
+	return nil
+}
+
+// uriAsset turns a relative URI into the asset name of the file it refers to.
+func (l *gltfLoader) uriAsset(uri string) (string, error) {
+	unescaped, err := url.PathUnescape(uri)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(l.dir, unescaped), nil
+}
+
+func decodeDataURI(uri string) ([]byte, error) {
+	comma := strings.IndexByte(uri, ',')
+	if comma < 0 || !strings.HasSuffix(uri[:comma], ";base64") {
+		return nil, fmt.Errorf("only base64 data URIs are supported")
+	}
+	return base64.StdEncoding.DecodeString(uri[comma+1:])
+}
+
+// materials adds the materials of the file to model and returns the name each one got. glTF doesn't require
+// materials to have a unique name, the ones without get one after the file, like "models/duck.gltf#material1",
+// which doesn't clash with the materials of other files.
+func (l *gltfLoader) materials(model *Model) ([]string, error) {
+	names := make([]string, len(l.doc.Materials))
+	for i, m := range l.doc.Materials {
+		name := m.Name
+		if _, taken := model.Materials[name]; name == "" || taken {
+			name = fmt.Sprintf("%s#material%d", l.name, i)
+		}
+		names[i] = name
+
+		pbr := m.PBRMetallicRoughness
+		material := Material{
+			Name:      name,
+			BaseColor: mgl.Vec4{1, 1, 1, 1},
+			Metallic:  1,
+			Roughness: 1,
+		}
+		if pbr.BaseColorFactor != nil {
+			if len(pbr.BaseColorFactor) != 4 {
+				return nil, fmt.Errorf("material %d: baseColorFactor has %d elements", i, len(pbr.BaseColorFactor))
+			}
+			copy(material.BaseColor[:], pbr.BaseColorFactor)
+		}
+		if pbr.MetallicFactor != nil {
+			material.Metallic = *pbr.MetallicFactor
+		}
+		if pbr.RoughnessFactor != nil {
+			material.Roughness = *pbr.RoughnessFactor
+		}
+
+		var err error
+		if material.BaseColorMap, err = l.texture(model, pbr.BaseColorTexture); err != nil {
+			return nil, fmt.Errorf("material %d: %v", i, err)
+		}
+		if material.MetallicRoughnessMap, err = l.texture(model, pbr.MetallicRoughnessTexture); err != nil {
+			return nil, fmt.Errorf("material %d: %v", i, err)
+		}
+		if material.NormalMap, err = l.texture(model, m.NormalTexture); err != nil {
+			return nil, fmt.Errorf("material %d: %v", i, err)
+		}
+
+		material.Diffuse = material.BaseColor.Vec3()
+		material.DiffuseMap = material.BaseColorMap
+		model.Materials[name] = material
+	}
+	return names, nil
+}
+
+// texture returns the name of the image a texture uses, storing images that are part of the file in model.Images.
+func (l *gltfLoader) texture(model *Model, info *gltfTextureInfo) (string, error) {
+	if info == nil {
+		return "", nil
+	}
+	if info.Index < 0 || info.Index >= len(l.doc.Textures) {
+		return "", fmt.Errorf("texture %d out of range", info.Index)
+	}
+	source := l.doc.Textures[info.Index].Source
+	if source == nil {
+		// the image comes from an extension, like KHR_texture_basisu
+		return "", nil
+	}
+	if *source < 0 || *source >= len(l.doc.Images) {
+		return "", fmt.Errorf("image %d out of range", *source)
+	}
+
+	image := l.doc.Images[*source]
+	if image.URI != "" && !strings.HasPrefix(image.URI, "data:") {
+		return l.uriAsset(image.URI)
+	}
+
+	name := fmt.Sprintf("%s#image%d", l.name, *source)
+	if _, loaded := model.Images[name]; loaded {
+		return name, nil
+	}
+
+	var data []byte
+	var err error
+	if image.URI != "" {
+		data, err = decodeDataURI(image.URI)
+	} else if image.BufferView != nil {
+		data, err = l.bufferView(*image.BufferView)
+	} else {
+		err = fmt.Errorf("image %d has neither a uri nor a bufferView", *source)
+	}
+	if err != nil {
+		return "", err
+	}
+	model.Images[name] = data
+	return name, nil
+}
+
+func (l *gltfLoader) bufferView(index int) ([]byte, error) {
+	if index < 0 || index >= len(l.doc.BufferViews) {
+		return nil, fmt.Errorf("bufferView %d out of range", index)
+	}
+	view := l.doc.BufferViews[index]
+	if view.Buffer < 0 || view.Buffer >= len(l.buffers) {
+		return nil, fmt.Errorf("bufferView %d: buffer %d out of range", index, view.Buffer)
+	}
+	buffer := l.buffers[view.Buffer]
+	if view.ByteOffset < 0 || view.ByteLength < 0 || view.ByteOffset > len(buffer) || view.ByteLength > len(buffer)-view.ByteOffset {
+		return nil, fmt.Errorf("bufferView %d reaches past the end of buffer %d", index, view.Buffer)
+	}
+	return buffer[view.ByteOffset : view.ByteOffset+view.ByteLength], nil
+}
+
+// accessor reads the elements of an accessor as floats, normalizing integer components if the accessor says so.
+// It returns the values and the number of components per element.
+func (l *gltfLoader) accessor(index int) ([]float32, int, error) {
+	if index < 0 || index >= len(l.doc.Accessors) {
+		return nil, 0, fmt.Errorf("accessor %d out of range", index)
+	}
+	accessor := l.doc.Accessors[index]
+	if accessor.Sparse != nil {
+		return nil, 0, fmt.Errorf("accessor %d: sparse accessors are not supported", index)
+	}
+	components, ok := gltfComponents[accessor.Type]
+	if !ok {
+		return nil, 0, fmt.Errorf("accessor %d: unknown type %q", index, accessor.Type)
+	}
+	size := componentSize(accessor.ComponentType)
+	if size == 0 {
+		return nil, 0, fmt.Errorf("accessor %d: unknown component type %d", index, accessor.ComponentType)
+	}
+	if accessor.Count < 0 || accessor.ByteOffset < 0 {
+		return nil, 0, fmt.Errorf("accessor %d: negative count or byteOffset", index)
+	}
+
+	if accessor.BufferView == nil {
+		// no data means all zeros
+		if accessor.Count > maxZeroAccessorCount {
+			return nil, 0, fmt.Errorf("accessor %d: %d elements without a bufferView", index, accessor.Count)
+		}
+		return make([]float32, accessor.Count*components), components, nil
+	}
+
+	data, err := l.bufferView(*accessor.BufferView)
+	if err != nil {
+		return nil, 0, fmt.Errorf("accessor %d: %v", index, err)
+	}
+	elementSize := size * components
+	stride := l.doc.BufferViews[*accessor.BufferView].ByteStride
+	if stride == 0 {
+		stride = elementSize
+	}
+	if stride < elementSize || stride > maxByteStride {
+		return nil, 0, fmt.Errorf("accessor %d: byteStride %d doesn't fit elements of %d bytes", index, stride, elementSize)
+	}
+	// compare the count before multiplying, so a huge count can't overflow
+	if accessor.ByteOffset > len(data) || accessor.Count > (len(data)-accessor.ByteOffset)/stride+1 ||
+		accessor.Count > 0 && accessor.ByteOffset+(accessor.Count-1)*stride+elementSize > len(data) {
+		return nil, 0, fmt.Errorf("accessor %d reaches past the end of its bufferView", index)
+	}
+
+	values := make([]float32, accessor.Count*components)
+	for i := 0; i < accessor.Count; i++ {
+		element := data[accessor.ByteOffset+i*stride:]
+		for c := 0; c < components; c++ {
+			values[i*components+c] = readComponent(element[c*size:], accessor.ComponentType, accessor.Normalized)
+		}
+	}
+	return values, components, nil
+}
+
+func componentSize(componentType int) int {
+	switch componentType {
+	case gltfByte, gltfUnsignedByte:
+		return 1
+	case gltfShort, gltfUnsignedShort:
+		return 2
+	case gltfUnsignedInt, gltfFloat:
+		return 4
+	}
+	return 0
+}
+
+func readComponent(b []byte, componentType int, normalized bool) float32 {
+	switch componentType {
+	case gltfFloat:
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	case gltfUnsignedInt:
+		return float32(binary.LittleEndian.Uint32(b))
+	case gltfUnsignedShort:
+		value := float32(binary.LittleEndian.Uint16(b))
+		if normalized {
+			return value / math.MaxUint16
+		}
+		return value
+	case gltfShort:
+		value := float32(int16(binary.LittleEndian.Uint16(b)))
+		if normalized {
+			return mgl.Clamp(value/math.MaxInt16, -1, 1)
+		}
+		return value
+	case gltfUnsignedByte:
+		if normalized {
+			return float32(b[0]) / math.MaxUint8
+		}
+		return float32(b[0])
+	case gltfByte:
+		if normalized {
+			return mgl.Clamp(float32(int8(b[0]))/math.MaxInt8, -1, 1)
+		}
+		return float32(int8(b[0]))
+	}
+	return 0
+}
+
+// indices reads an index accessor. Indices are integers, they are read directly instead of going through floats,
+// which can't hold every 32 bit index.
+func (l *gltfLoader) indices(index int, vertexCount int) ([]uint32, error) {
+	if index < 0 || index >= len(l.doc.Accessors) {
+		return nil, fmt.Errorf("accessor %d out of range", index)
+	}
+	accessor := l.doc.Accessors[index]
+	size := componentSize(accessor.ComponentType)
+	if accessor.Type != "SCALAR" || accessor.ComponentType == gltfFloat || size == 0 {
+		return nil, fmt.Errorf("accessor %d can't hold indices", index)
+	}
+	if accessor.BufferView == nil || accessor.Sparse != nil {
+		return nil, fmt.Errorf("accessor %d: indices need a bufferView and can't be sparse", index)
+	}
+
+	data, err := l.bufferView(*accessor.BufferView)
+	if err != nil {
+		return nil, fmt.Errorf("accessor %d: %v", index, err)
+	}
+	if accessor.Count < 0 || accessor.ByteOffset < 0 || accessor.ByteOffset > len(data) || accessor.Count > (len(data)-accessor.ByteOffset)/size {
+		return nil, fmt.Errorf("accessor %d reaches past the end of its bufferView", index)
+	}
+
+	indices := make([]uint32, accessor.Count)
+	for i := range indices {
+		b := data[accessor.ByteOffset+i*size:]
+		switch size {
+		case 1:
+			indices[i] = uint32(b[0])
+		case 2:
+			indices[i] = uint32(binary.LittleEndian.Uint16(b))
+		case 4:
+			indices[i] = binary.LittleEndian.Uint32(b)
+		}
+		if int(indices[i]) >= vertexCount {
+			return nil, fmt.Errorf("accessor %d: index %d out of range, there are %d vertices", index, indices[i], vertexCount)
+		}
+	}
+	return indices, nil
+}
+
+// primitive interleaves the attributes of a primitive in the VertexStride or TangentStride layout and turns it
+// into a triangle list.
+func (l *gltfLoader) primitive(primitive gltfPrimitive) (MeshData, error) {
+	mode := gltfTriangles
+	if primitive.Mode != nil {
+		mode = *primitive.Mode
+	}
+	if mode != gltfTriangles && mode != gltfTriangleStrip && mode != gltfTriangleFan {
+		return MeshData{}, fmt.Errorf("mode %d is not made of triangles", mode)
+	}
+
+	positionAccessor, ok := primitive.Attributes["POSITION"]
+	if !ok {
+		return MeshData{}, fmt.Errorf("no POSITION attribute")
+	}
+	positions, err := l.attribute(positionAccessor, 3)
+	if err != nil {
+		return MeshData{}, err
+	}
+	count := len(positions) / 3
+
+	var normals, texCoords, tangents []float32
+	if accessor, ok := primitive.Attributes["NORMAL"]; ok {
+		if normals, err = l.attribute(accessor, 3); err != nil {
+			return MeshData{}, err
+		}
+	}
+	// tangents belong to the normals, without normals they are ignored
+	if accessor, ok := primitive.Attributes["TANGENT"]; ok && normals != nil {
+		if tangents, err = l.attribute(accessor, 4); err != nil {
+			return MeshData{}, err
+		}
+	}
+	if accessor, ok := primitive.Attributes["TEXCOORD_0"]; ok {
+		if texCoords, err = l.attribute(accessor, 2); err != nil {
+			return MeshData{}, err
+		}
+	}
+	if normals != nil && len(normals)/3 != count || texCoords != nil && len(texCoords)/2 != count || tangents != nil && len(tangents)/4 != count {
+		return MeshData{}, fmt.Errorf("the attributes have different counts")
+	}
+
+	var indices []uint32
+	if primitive.Indices != nil {
+		if indices, err = l.indices(*primitive.Indices, count); err != nil {
+			return MeshData{}, err
+		}
+	} else {
+		indices = make([]uint32, count)
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+	indices = triangleList(indices, mode)
+
+	mesh := MeshData{Indices: indices, Stride: VertexStride}
+	if tangents != nil {
+		mesh.Stride = TangentStride
+	}
+	mesh.Vertices = make([]float32, 0, count*mesh.Stride)
+	for i := 0; i < count; i++ {
+		mesh.Vertices = append(mesh.Vertices, positions[i*3:i*3+3]...)
+		if normals != nil {
+			mesh.Vertices = append(mesh.Vertices, normals[i*3:i*3+3]...)
+		} else {
+			mesh.Vertices = append(mesh.Vertices, 0, 0, 0)
+		}
+		if texCoords != nil {
+			// glTF puts the origin of texture space at the top left, OpenGL at the bottom left
+			mesh.Vertices = append(mesh.Vertices, texCoords[i*2], 1-texCoords[i*2+1])
+		} else {
+			mesh.Vertices = append(mesh.Vertices, 0, 0)
+		}
+		if tangents != nil {
+			// glTF normal maps point +Y up the image like OpenGL ones, flipping v keeps the handedness as it is
+			mesh.Vertices = append(mesh.Vertices, tangents[i*4:i*4+4]...)
+		}
+	}
+
+	if normals == nil {
+		generateNormals(mesh.Vertices, mesh.Indices)
+	}
+	return mesh, nil
+}
+
+func (l *gltfLoader) attribute(index int, components int) ([]float32, error) {
+	values, got, err := l.accessor(index)
+	if err != nil {
+		return nil, err
+	}
+	if got != components {
+		return nil, fmt.Errorf("accessor %d has %d components, expected %d", index, got, components)
+	}
+	return values, nil
+}
+
+// triangleList turns the indices of a triangle strip or fan into a list of separate triangles.
+func triangleList(indices []uint32, mode int) []uint32 {
+	if mode == gltfTriangles || len(indices) < 3 {
+		return indices[:len(indices)/3*3]
+	}
+
+	list := make([]uint32, 0, (len(indices)-2)*3)
+	for i := 2; i < len(indices); i++ {
+		switch {
+		case mode == gltfTriangleFan:
+			list = append(list, indices[0], indices[i-1], indices[i])
+		case i%2 == 0:
+			list = append(list, indices[i-2], indices[i-1], indices[i])
+		default:
+			// every other triangle of a strip is flipped, swap two corners to keep the winding
+			list = append(list, indices[i-1], indices[i-2], indices[i])
+		}
+	}
+	return list
+}
+
+// generateNormals sets the normals of vertices in the VertexStride layout to the area weighted average
+// of the normals of the triangles using them.
+func generateNormals(vertices []float32, indices []uint32) {
+	position := func(index uint32) mgl.Vec3 {
+		v := vertices[int(index)*VertexStride:]
+		return mgl.Vec3{v[0], v[1], v[2]}
+	}
+
+	sums := make([]mgl.Vec3, len(vertices)/VertexStride)
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := position(indices[i]), position(indices[i+1]), position(indices[i+2])
+		faceNormal := b.Sub(a).Cross(c.Sub(a))
+		for _, index := range indices[i : i+3] {
+			sums[index] = sums[index].Add(faceNormal)
+		}
+	}
+
+	for i, sum := range sums {
+		normal := mgl.Vec3{0, 1, 0}
+		if sum.Len() > 0 {
+			normal = sum.Normalize()
+		}
+		copy(vertices[i*VertexStride+3:i*VertexStride+6], normal[:])
+	}
+}
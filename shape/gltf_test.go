@@ -0,0 +1,384 @@
+package shape
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// triangleBuffer holds a triangle: three positions, normals and tangents as floats, then three uint16 indices.
+func triangleBuffer() []byte {
+	var b bytes.Buffer
+	for _, values := range [][]float32{
+		{0, 0, 0, 1, 0, 0, 0, 1, 0},           // positions
+		{0, 0, 1, 0, 0, 1, 0, 0, 1},           // normals
+		{1, 0, 0, 1, 1, 0, 0, 1, 1, 0, 0, -1}, // tangents
+	} {
+		_ = binary.Write(&b, binary.LittleEndian, values)
+	}
+	_ = binary.Write(&b, binary.LittleEndian, []uint16{0, 1, 2, 0})
+	return b.Bytes()
+}
+
+// triangleGLTF returns a glTF document drawing the triangle, with change applied to the decoded JSON first.
+func triangleGLTF(t *testing.T, change func(doc map[string]interface{})) []byte {
+	buffer := triangleBuffer()
+	doc := map[string]interface{}{
+		"asset":   map[string]interface{}{"version": "2.0"},
+		"buffers": []interface{}{map[string]interface{}{"uri": "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(buffer), "byteLength": len(buffer)}},
+		"bufferViews": []interface{}{
+			map[string]interface{}{"buffer": 0, "byteOffset": 0, "byteLength": 36},
+			map[string]interface{}{"buffer": 0, "byteOffset": 36, "byteLength": 36},
+			map[string]interface{}{"buffer": 0, "byteOffset": 72, "byteLength": 48},
+			map[string]interface{}{"buffer": 0, "byteOffset": 120, "byteLength": 6},
+		},
+		"accessors": []interface{}{
+			map[string]interface{}{"bufferView": 0, "componentType": gltfFloat, "count": 3, "type": "VEC3"},
+			map[string]interface{}{"bufferView": 1, "componentType": gltfFloat, "count": 3, "type": "VEC3"},
+			map[string]interface{}{"bufferView": 2, "componentType": gltfFloat, "count": 3, "type": "VEC4"},
+			map[string]interface{}{"bufferView": 3, "componentType": gltfUnsignedShort, "count": 3, "type": "SCALAR"},
+		},
+		"meshes": []interface{}{map[string]interface{}{"primitives": []interface{}{map[string]interface{}{
+			"attributes": map[string]interface{}{"POSITION": 0, "NORMAL": 1},
+			"indices":    3,
+		}}}},
+		"nodes": []interface{}{map[string]interface{}{"mesh": 0}},
+	}
+	if change != nil {
+		change(doc)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func noFiles(name string) ([]byte, error) {
+	return nil, fmt.Errorf("no file %q", name)
+}
+
+// set returns a change setting key of the element at index of the list called list to value.
+func set(list string, index int, key string, value interface{}) func(doc map[string]interface{}) {
+	return func(doc map[string]interface{}) {
+		doc[list].([]interface{})[index].(map[string]interface{})[key] = value
+	}
+}
+
+func TestReadGLTF(t *testing.T) {
+	model, err := ReadGLTF(triangleGLTF(t, nil), "triangle.gltf", noFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(model.Meshes) != 1 || model.Meshes[0].Stride != VertexStride || len(model.Meshes[0].Indices) != 3 {
+		t.Fatalf("got %+v", model.Meshes)
+	}
+	if len(model.Roots) != 1 || len(model.WorldTransforms()) != 1 {
+		t.Errorf("got roots %v", model.Roots)
+	}
+}
+
+func TestReadGLTFTangents(t *testing.T) {
+	data := triangleGLTF(t, func(doc map[string]interface{}) {
+		primitive := doc["meshes"].([]interface{})[0].(map[string]interface{})["primitives"].([]interface{})[0].(map[string]interface{})
+		primitive["attributes"].(map[string]interface{})["TANGENT"] = 2
+	})
+	model, err := ReadGLTF(data, "triangle.gltf", noFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mesh := model.Meshes[0]
+	if mesh.Stride != TangentStride {
+		t.Fatalf("stride %d, want %d", mesh.Stride, TangentStride)
+	}
+	last := mesh.Vertices[2*TangentStride:]
+	if got := last[8:12]; got[0] != 1 || got[3] != -1 {
+		t.Errorf("third tangent is %v, want [1 0 0 -1]", got)
+	}
+}
+
+func TestReadGLTFBadAccessors(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(doc map[string]interface{})
+	}{
+		{"negative byteOffset", set("accessors", 0, "byteOffset", -4)},
+		{"byteOffset past the end", set("accessors", 0, "byteOffset", 1<<40)},
+		{"negative count", set("accessors", 0, "count", -1)},
+		{"huge count", set("accessors", 0, "count", 1<<60)},
+		{"huge count without data", func(doc map[string]interface{}) {
+			set("accessors", 0, "count", 1<<60)(doc)
+			delete(doc["accessors"].([]interface{})[0].(map[string]interface{}), "bufferView")
+		}},
+		{"negative byteStride", set("bufferViews", 0, "byteStride", -12)},
+		{"byteStride smaller than an element", set("bufferViews", 0, "byteStride", 4)},
+		{"huge byteStride", set("bufferViews", 0, "byteStride", 1<<40)},
+		{"negative index byteOffset", set("accessors", 3, "byteOffset", -2)},
+		{"huge index count", set("accessors", 3, "count", 1<<60)},
+		{"negative index count", set("accessors", 3, "count", -3)},
+		{"index out of range", set("accessors", 3, "count", 4)},
+		{"bufferView past the end", set("bufferViews", 3, "byteLength", 1<<62)},
+		{"negative bufferView byteOffset", set("bufferViews", 3, "byteOffset", -1)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ReadGLTF(triangleGLTF(t, test.change), "triangle.gltf", noFiles)
+			if err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestReadGLTFHierarchy(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes string
+		valid bool
+	}{
+		{"tree", `[{"children": [1, 2]}, {"mesh": 0}, {"children": [3]}, {"mesh": 0}]`, true},
+		{"own child", `[{"children": [0]}]`, false},
+		{"cycle", `[{"children": [1]}, {"children": [2]}, {"children": [0]}]`, false},
+		{"cycle below a root", `[{"children": [1]}, {"children": [2]}, {"children": [1]}]`, false},
+		{"two parents", `[{"children": [2]}, {"children": [2]}, {"mesh": 0}]`, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := triangleGLTF(t, func(doc map[string]interface{}) {
+				var nodes []interface{}
+				if err := json.Unmarshal([]byte(test.nodes), &nodes); err != nil {
+					t.Fatal(err)
+				}
+				doc["nodes"] = nodes
+				doc["scenes"] = []interface{}{map[string]interface{}{"nodes": []int{0}}}
+			})
+			model, err := ReadGLTF(data, "nodes.gltf", noFiles)
+			if test.valid {
+				if err != nil {
+					t.Fatal(err)
+				}
+				model.WorldTransforms()
+			} else if err == nil || !strings.Contains(err.Error(), "node") {
+				t.Errorf("got error %v, want one about the nodes", err)
+			}
+		})
+	}
+}
+
+// glb packs a JSON document and a binary buffer into a .glb file, padding the chunks to 4 bytes.
+func glb(jsonData []byte, bin []byte) []byte {
+	chunk := func(data []byte, chunkType uint32, pad byte) []byte {
+		for len(data)%4 != 0 {
+			data = append(data, pad)
+		}
+		header := make([]byte, 8)
+		binary.LittleEndian.PutUint32(header, uint32(len(data)))
+		binary.LittleEndian.PutUint32(header[4:], chunkType)
+		return append(header, data...)
+	}
+	chunks := append(chunk(append([]byte{}, jsonData...), 0x4E4F534A, ' '), chunk(append([]byte{}, bin...), 0x004E4942, 0)...)
+
+	header := make([]byte, 12)
+	copy(header, "glTF")
+	binary.LittleEndian.PutUint32(header[4:], 2)
+	binary.LittleEndian.PutUint32(header[8:], uint32(12+len(chunks)))
+	return append(header, chunks...)
+}
+
+func TestReadGLB(t *testing.T) {
+	image := []byte("not really a png")
+	bin := append(triangleBuffer(), image...)
+	jsonData := triangleGLTF(t, func(doc map[string]interface{}) {
+		// the buffer without a uri is the binary chunk, and the image is stored in it too
+		doc["buffers"] = []interface{}{map[string]interface{}{"byteLength": len(bin)}}
+		doc["bufferViews"] = append(doc["bufferViews"].([]interface{}),
+			map[string]interface{}{"buffer": 0, "byteOffset": len(bin) - len(image), "byteLength": len(image)})
+		doc["images"] = []interface{}{map[string]interface{}{"bufferView": 4, "mimeType": "image/png"}}
+		doc["textures"] = []interface{}{map[string]interface{}{"source": 0}}
+		doc["materials"] = []interface{}{map[string]interface{}{
+			"pbrMetallicRoughness": map[string]interface{}{"baseColorTexture": map[string]interface{}{"index": 0}},
+		}}
+		set("meshes", 0, "primitives", []interface{}{map[string]interface{}{
+			"attributes": map[string]interface{}{"POSITION": 0, "NORMAL": 1},
+			"indices":    3,
+			"material":   0,
+		}})(doc)
+	})
+	data := glb(jsonData, bin)
+
+	model, err := ReadGLTF(data, "models/triangle.glb", noFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(model.Meshes) != 1 || len(model.Meshes[0].Indices) != 3 {
+		t.Fatalf("got meshes %+v", model.Meshes)
+	}
+	material := model.Materials[model.Meshes[0].Material]
+	if material.DiffuseMap != "models/triangle.glb#image0" || !bytes.Equal(model.Images[material.DiffuseMap], image) {
+		t.Errorf("got diffuse map %q holding %q", material.DiffuseMap, model.Images[material.DiffuseMap])
+	}
+
+	for name, bad := range map[string][]byte{
+		"truncated header": data[:16],
+		"truncated chunk":  data[:len(data)-4],
+		"version 1":        append([]byte("glTF\x01\x00\x00\x00"), data[8:]...),
+		"no JSON":          glb(nil, bin)[:12+8+len(bin)],
+	} {
+		if _, err := ReadGLTF(bad, "models/bad.glb", noFiles); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestReadGLTFNodeTransforms(t *testing.T) {
+	// a quarter turn around Y, as x, y, z, w
+	s := float32(math.Sqrt2 / 2)
+	data := triangleGLTF(t, func(doc map[string]interface{}) {
+		doc["nodes"] = []interface{}{
+			map[string]interface{}{
+				"translation": []float32{1, 2, 3},
+				"rotation":    []float32{0, s, 0, s},
+				"scale":       []float32{2, 2, 2},
+				"children":    []int{1, 2},
+			},
+			map[string]interface{}{"translation": []float32{0, 1, 0}, "mesh": 0},
+			// column by column
+			map[string]interface{}{"matrix": []float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 5, 0, 0, 1}},
+		}
+	})
+	model, err := ReadGLTF(data, "nodes.gltf", noFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(model.Roots) != 1 || model.Roots[0] != 0 {
+		t.Fatalf("got roots %v, want [0]", model.Roots)
+	}
+
+	// scale first, then rotate, then translate, and the children go through their parent
+	transforms := model.WorldTransforms()
+	tests := []struct {
+		node  int
+		point mgl.Vec3
+		want  mgl.Vec3
+	}{
+		{0, mgl.Vec3{1, 0, 0}, mgl.Vec3{1, 2, 1}},
+		{0, mgl.Vec3{0, 0, 1}, mgl.Vec3{3, 2, 3}},
+		{1, mgl.Vec3{0, 0, 0}, mgl.Vec3{1, 4, 3}},
+		{1, mgl.Vec3{1, 0, 0}, mgl.Vec3{1, 4, 1}},
+		{2, mgl.Vec3{0, 0, 0}, mgl.Vec3{1, 2, -7}},
+	}
+	for _, test := range tests {
+		if got := mgl.TransformCoordinate(test.point, transforms[test.node]); got.Sub(test.want).Len() > 1e-5 {
+			t.Errorf("node %d moves %v to %v, want %v", test.node, test.point, got, test.want)
+		}
+	}
+
+	for name, node := range map[string]map[string]interface{}{
+		"short translation": {"translation": []float32{1, 2}},
+		"short rotation":    {"rotation": []float32{0, 0, 1}},
+		"long scale":        {"scale": []float32{1, 1, 1, 1}},
+		"short matrix":      {"matrix": []float32{1, 0, 0, 0}},
+	} {
+		data := triangleGLTF(t, func(doc map[string]interface{}) { doc["nodes"] = []interface{}{node} })
+		if _, err := ReadGLTF(data, "bad.gltf", noFiles); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestReadGLTFMaterials(t *testing.T) {
+	image := "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("png"))
+	data := triangleGLTF(t, func(doc map[string]interface{}) {
+		doc["images"] = []interface{}{
+			map[string]interface{}{"uri": image},
+			map[string]interface{}{"uri": "textures/brick%20normal.png"},
+		}
+		doc["textures"] = []interface{}{map[string]interface{}{"source": 0}, map[string]interface{}{"source": 1}}
+		doc["materials"] = []interface{}{
+			map[string]interface{}{
+				"name": "brick",
+				"pbrMetallicRoughness": map[string]interface{}{
+					"baseColorFactor":          []float32{0.5, 0.25, 1, 0.75},
+					"baseColorTexture":         map[string]interface{}{"index": 0},
+					"metallicFactor":           0,
+					"roughnessFactor":          0.5,
+					"metallicRoughnessTexture": map[string]interface{}{"index": 0},
+				},
+				"normalTexture": map[string]interface{}{"index": 1},
+			},
+			map[string]interface{}{},
+			map[string]interface{}{"name": "brick"},
+		}
+		primitive := func(material int) interface{} {
+			return map[string]interface{}{"attributes": map[string]interface{}{"POSITION": 0}, "indices": 3, "material": material}
+		}
+		set("meshes", 0, "primitives", []interface{}{primitive(0), primitive(1), primitive(2)})(doc)
+	})
+	model, err := ReadGLTF(data, "models/wall.gltf", noFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// unnamed and duplicate names are made unique with the name of the file
+	wantNames := []string{"brick", "models/wall.gltf#material1", "models/wall.gltf#material2"}
+	if len(model.Meshes) != len(wantNames) {
+		t.Fatalf("got %d meshes, want %d", len(model.Meshes), len(wantNames))
+	}
+	for i, want := range wantNames {
+		if model.Meshes[i].Material != want {
+			t.Errorf("mesh %d has material %q, want %q", i, model.Meshes[i].Material, want)
+		}
+		if model.Materials[want].Name != want {
+			t.Errorf("material %q is called %q", want, model.Materials[want].Name)
+		}
+	}
+
+	brick := model.Materials["brick"]
+	embedded := "models/wall.gltf#image0"
+	switch {
+	case brick.BaseColor != (mgl.Vec4{0.5, 0.25, 1, 0.75}) || brick.Diffuse != (mgl.Vec3{0.5, 0.25, 1}):
+		t.Errorf("got base colour %v and diffuse %v", brick.BaseColor, brick.Diffuse)
+	case brick.Metallic != 0 || brick.Roughness != 0.5:
+		t.Errorf("got metallic %g and roughness %g", brick.Metallic, brick.Roughness)
+	case brick.BaseColorMap != embedded || brick.DiffuseMap != embedded || brick.MetallicRoughnessMap != embedded:
+		t.Errorf("got maps %q, %q and %q, want %q", brick.BaseColorMap, brick.DiffuseMap, brick.MetallicRoughnessMap, embedded)
+	case string(model.Images[embedded]) != "png" || len(model.Images) != 1:
+		t.Errorf("got images %q", model.Images)
+	case brick.NormalMap != "models/textures/brick normal.png":
+		t.Errorf("got normal map %q", brick.NormalMap)
+	}
+
+	// the defaults of the specification
+	plain := model.Materials["models/wall.gltf#material1"]
+	if plain.BaseColor != (mgl.Vec4{1, 1, 1, 1}) || plain.Metallic != 1 || plain.Roughness != 1 || plain.DiffuseMap != "" {
+		t.Errorf("got %+v", plain)
+	}
+
+	for name, change := range map[string]func(doc map[string]interface{}){
+		"material out of range": set("meshes", 0, "primitives", []interface{}{map[string]interface{}{
+			"attributes": map[string]interface{}{"POSITION": 0}, "material": 1,
+		}}),
+		"texture out of range": set("materials", 0, "normalTexture", map[string]interface{}{"index": 5}),
+		"image out of range":   set("textures", 0, "source", 7),
+		"short colour":         set("materials", 0, "pbrMetallicRoughness", map[string]interface{}{"baseColorFactor": []float32{1, 1, 1}}),
+	} {
+		bad := triangleGLTF(t, func(doc map[string]interface{}) {
+			doc["images"] = []interface{}{map[string]interface{}{"uri": image}}
+			doc["textures"] = []interface{}{map[string]interface{}{"source": 0}}
+			doc["materials"] = []interface{}{map[string]interface{}{
+				"pbrMetallicRoughness": map[string]interface{}{"baseColorTexture": map[string]interface{}{"index": 0}},
+			}}
+			change(doc)
+		})
+		if _, err := ReadGLTF(bad, "bad.gltf", noFiles); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
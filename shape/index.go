@@ -0,0 +1,31 @@
+package shape
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Index turns a list of triangles like Cube, stride floats per vertex, into indexed form: every distinct vertex
+// is kept once and the indices refer to it. Vertices are only merged if all their floats are identical.
+func Index(vertices []float32, stride int) ([]float32, []uint32) {
+	seen := map[string]uint32{}
+	var unique []float32
+	indices := make([]uint32, 0, len(vertices)/stride)
+
+	key := make([]byte, stride*4)
+	for start := 0; start+stride <= len(vertices); start += stride {
+		vertex := vertices[start : start+stride]
+		for i, f := range vertex {
+			binary.LittleEndian.PutUint32(key[i*4:], math.Float32bits(f))
+		}
+
+		index, ok := seen[string(key)]
+		if !ok {
+			index = uint32(len(unique) / stride)
+			seen[string(key)] = index
+			unique = append(unique, vertex...)
+		}
+		indices = append(indices, index)
+	}
+	return unique, indices
+}
@@ -0,0 +1,67 @@
+package shape
+
+import (
+	"math"
+	"testing"
+)
+
+// deindex expands indexed vertices back into a list of triangles.
+func deindex(vertices []float32, indices []uint32, stride int) []float32 {
+	var expanded []float32
+	for _, index := range indices {
+		expanded = append(expanded, vertices[int(index)*stride:int(index+1)*stride]...)
+	}
+	return expanded
+}
+
+func TestIndexCube(t *testing.T) {
+	vertices, indices := Index(Cube, VertexStride)
+
+	// every face has 4 corners of its own, with the normal of the face
+	if count := len(vertices) / VertexStride; count != 24 || len(vertices)%VertexStride != 0 {
+		t.Errorf("got %d floats, %d vertices, want 24", len(vertices), count)
+	}
+	if len(indices) != 36 {
+		t.Errorf("got %d indices, want 36", len(indices))
+	}
+	for _, index := range indices {
+		if int(index) >= len(vertices)/VertexStride {
+			t.Fatalf("index %d out of range", index)
+		}
+	}
+	if !equalFloats(deindex(vertices, indices, VertexStride), Cube) {
+		t.Error("the indexed triangles differ from Cube")
+	}
+}
+
+func TestIndex(t *testing.T) {
+	negativeZero := float32(math.Copysign(0, -1))
+	tests := []struct {
+		name     string
+		vertices []float32
+		stride   int
+		unique   int
+		indices  []uint32
+	}{
+		{"empty", nil, 2, 0, []uint32{}},
+		{"all different", []float32{0, 0, 1, 0, 0, 1}, 2, 3, []uint32{0, 1, 2}},
+		{"repeated", []float32{0, 0, 1, 0, 0, 0, 1, 0}, 2, 2, []uint32{0, 1, 0, 1}},
+		// vertices sharing a position but not the other floats, like the corners of Cube, stay apart
+		{"differ in the last float", []float32{1, 2, 3, 1, 2, 4, 1, 2, 3}, 3, 2, []uint32{0, 1, 0}},
+		// only identical bits are merged
+		{"signed zero", []float32{0, negativeZero}, 1, 2, []uint32{0, 1}},
+		// a partial vertex at the end is dropped
+		{"trailing floats", []float32{5, 6, 5, 6, 5}, 2, 1, []uint32{0, 0}},
+	}
+	for _, test := range tests {
+		vertices, indices := Index(test.vertices, test.stride)
+		if len(vertices) != test.unique*test.stride || !equalIndices(indices, test.indices) {
+			t.Errorf("%s: got %v %v, want %d vertices and indices %v", test.name, vertices, indices, test.unique, test.indices)
+			continue
+		}
+		whole := test.vertices[:len(test.vertices)/test.stride*test.stride]
+		if !equalFloats(deindex(vertices, indices, test.stride), whole) {
+			t.Errorf("%s: the indexed vertices differ from the input", test.name)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package shape
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strings"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// VertexStride is the number of floats per vertex in the meshes of a Model, laid out like Cube:
+// position (3), normal (3) and texture coordinates (2).
+const VertexStride = 8
+
+// TangentStride is the number of floats per vertex in the layout with tangents, used by the generated meshes:
+// the VertexStride layout followed by a tangent (3) and its handedness (1), which is the sign the
+// bitangent cross(normal, tangent) has to be multiplied with to point along increasing texture coordinate v.
+const TangentStride = 12
+
+// Model is geometry loaded from a file, split into meshes that each use one material.
+type Model struct {
+	Meshes    []MeshData
+	Materials map[string]Material
+	// Nodes place the meshes in the model. Roots are the indices of the nodes without a parent.
+	Nodes []Node
+	Roots []int
+	// Images holds the texture images stored inside the model file, by the name the materials use for them.
+	Images map[string][]byte
+}
+
+// Node is a transform in the hierarchy of a Model, with the meshes drawn at it.
+type Node struct {
+	Name string
+	// Transform is relative to the parent node.
+	Transform mgl.Mat4
+	// Meshes and Children are indices into Model.Meshes and Model.Nodes.
+	Meshes   []int
+	Children []int
+}
+
+// IsModelFile tells whether name is a model asset LoadModel can read, judging by its extension.
+func IsModelFile(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".obj", ".gltf", ".glb":
+		return true
+	}
+	return false
+}
+
+// LoadModel loads the OBJ or glTF asset called name, picking the loader by the extension.
+func LoadModel(name string) (*Model, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".obj":
+		return LoadOBJ(name)
+	case ".gltf", ".glb":
+		return LoadGLTF(name)
+	}
+	return nil, fmt.Errorf("%q is not an .obj, .gltf or .glb model", name)
+}
+
+// NewModel returns a model holding just mesh, without materials.
+func NewModel(mesh MeshData) *Model {
+	return &Model{
+		Meshes:    []MeshData{mesh},
+		Materials: map[string]Material{},
+		Nodes:     []Node{{Name: mesh.Name, Transform: mgl.Ident4(), Meshes: []int{0}}},
+		Roots:     []int{0},
+	}
+}
+
+// Bounds returns the corners of the box around every mesh of the model, placed by the nodes.
+// A model without vertices has the box around the origin.
+func (m *Model) Bounds() (mgl.Vec3, mgl.Vec3) {
+	inf := float32(math.Inf(1))
+	min, max := mgl.Vec3{inf, inf, inf}, mgl.Vec3{-inf, -inf, -inf}
+	for node, transform := range m.WorldTransforms() {
+		for _, mesh := range m.Nodes[node].Meshes {
+			data := m.Meshes[mesh]
+			for i := 0; i+2 < len(data.Vertices); i += data.Stride {
+				position := mgl.TransformCoordinate(mgl.Vec3{data.Vertices[i], data.Vertices[i+1], data.Vertices[i+2]}, transform)
+				for c := 0; c < 3; c++ {
+					min[c] = float32(math.Min(float64(min[c]), float64(position[c])))
+					max[c] = float32(math.Max(float64(max[c]), float64(position[c])))
+				}
+			}
+		}
+	}
+	if min[0] > max[0] {
+		return mgl.Vec3{}, mgl.Vec3{}
+	}
+	return min, max
+}
+
+// WorldTransforms returns the transform of every node relative to the model, indexed like Model.Nodes.
+func (m *Model) WorldTransforms() []mgl.Mat4 {
+	transforms := make([]mgl.Mat4, len(m.Nodes))
+	var visit func(node int, parent mgl.Mat4)
+	visit = func(node int, parent mgl.Mat4) {
+		transforms[node] = parent.Mul4(m.Nodes[node].Transform)
+		for _, child := range m.Nodes[node].Children {
+			visit(child, transforms[node])
+		}
+	}
+	for _, root := range m.Roots {
+		visit(root, mgl.Ident4())
+	}
+	return transforms
+}
+
+// MeshData is indexed triangle geometry, wound counter-clockwise.
+type MeshData struct {
+	Name     string
+	Vertices []float32
+	Indices  []uint32
+	// Stride is the number of floats per vertex, VertexStride or TangentStride.
+	Stride int
+	// Material names an entry in Model.Materials, empty when the faces have no material.
+	Material string
+}
+
+// Material is a material of a Model. Texture maps are asset names or keys of Model.Images, empty when
+// the material has none.
+type Material struct {
+	Name      string
+	Ambient   mgl.Vec3
+	Diffuse   mgl.Vec3
+	Specular  mgl.Vec3
+	Shininess float32
+
+	DiffuseMap  string
+	SpecularMap string
+	NormalMap   string
+
+	// The metallic-roughness parameters of glTF materials. Their base colour is copied to Diffuse and
+	// DiffuseMap as well, so they can be drawn with the other materials.
+	BaseColor            mgl.Vec4
+	Metallic             float32
+	Roughness            float32
+	BaseColorMap         string
+	MetallicRoughnessMap string
+}
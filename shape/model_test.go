@@ -0,0 +1,22 @@
+package shape
+
+import "testing"
+
+func TestGeneratedBounds(t *testing.T) {
+	for name := range generators {
+		mesh, ok := Generated(name)
+		if !ok {
+			t.Fatalf("%s is not generated", name)
+		}
+		min, max := NewModel(mesh).Bounds()
+		for c := 0; c < 3; c++ {
+			if min[c] < -0.5001 || max[c] > 0.5001 {
+				t.Errorf("%s reaches from %v to %v, outside the unit cube", name, min, max)
+				break
+			}
+		}
+	}
+	if _, ok := Generated("teapot"); ok {
+		t.Error("teapot is generated")
+	}
+}
@@ -0,0 +1,90 @@
+package shape
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// readMTL parses a Wavefront material library. Texture maps are resolved relative to dir.
+func readMTL(r io.Reader, name string, dir string) (map[string]Material, error) {
+	materials := map[string]Material{}
+	var current *Material
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		fields := strings.Fields(stripComment(scanner.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+
+		if fields[0] == "newmtl" {
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("%s:%d: newmtl without a name", name, lineNumber)
+			}
+			if current != nil {
+				materials[current.Name] = *current
+			}
+			current = &Material{Name: fields[1], Diffuse: mgl.Vec3{1, 1, 1}}
+			continue
+		}
+		if current == nil {
+			// statements before the first newmtl have nothing to apply to
+			continue
+		}
+
+		var err error
+		switch fields[0] {
+		case "Ka":
+			current.Ambient, err = parseVec3(fields[1:])
+		case "Kd":
+			current.Diffuse, err = parseVec3(fields[1:])
+		case "Ks":
+			current.Specular, err = parseVec3(fields[1:])
+		case "Ns":
+			var values []float32
+			values, err = parseFloats(fields[1:], 1, 1)
+			if err == nil {
+				current.Shininess = values[0]
+			}
+		case "map_Kd":
+			current.DiffuseMap, err = textureMap(fields, dir)
+		case "map_Ks":
+			current.SpecularMap, err = textureMap(fields, dir)
+		case "map_Bump", "map_bump", "bump", "norm":
+			current.NormalMap, err = textureMap(fields, dir)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s: %v", name, lineNumber, fields[0], err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	if current != nil {
+		materials[current.Name] = *current
+	}
+	return materials, nil
+}
+
+// textureMap returns the asset name of a map statement. Options like "-bm 1.0" come before the file name,
+// which is taken to be the last field.
+func textureMap(fields []string, dir string) (string, error) {
+	if len(fields) < 2 {
+		return "", fmt.Errorf("missing file name")
+	}
+	return path.Join(dir, strings.ReplaceAll(fields[len(fields)-1], "\\", "/")), nil
+}
+
+func parseVec3(fields []string) (mgl.Vec3, error) {
+	values, err := parseFloats(fields, 3, 3)
+	if err != nil {
+		return mgl.Vec3{}, err
+	}
+	return mgl.Vec3{values[0], values[1], values[2]}, nil
+}
@@ -0,0 +1,435 @@
+package shape
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/PetrusJPrinsloo/learnopengl/asset"
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// LoadOBJ loads the Wavefront OBJ asset called name, together with the material libraries it uses.
+func LoadOBJ(name string) (*Model, error) {
+	file, err := asset.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadOBJ(file, name, asset.Open)
+}
+
+// ReadOBJ parses an OBJ file called name from r. Material libraries and the textures they use are
+// resolved relative to name; open reads the libraries.
+//
+// Polygons with more than three corners are triangulated, negative (relative) indices are supported and
+// vertices without a normal get a smooth normal averaged from the faces around them. The faces are split
+// into one mesh per material.
+func ReadOBJ(r io.Reader, name string, open func(name string) (io.ReadCloser, error)) (*Model, error) {
+	p := &objParser{
+		name:      name,
+		dir:       path.Dir(name),
+		open:      open,
+		materials: map[string]Material{},
+		groups:    map[string]*objGroup{},
+	}
+
+	scanner := bufio.NewScanner(r)
+	for p.line = 1; scanner.Scan(); p.line++ {
+		fields := strings.Fields(stripComment(scanner.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+		if err := p.parse(fields); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s: %v", name, p.line, fields[0], err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return p.model(), nil
+}
+
+// objCorner is a corner of a face: indices into the positions, texture coordinates and normals, -1 when absent.
+type objCorner struct {
+	position, texCoord, normal int
+}
+
+// objGroup collects the triangles using one material.
+type objGroup struct {
+	name      string
+	material  string
+	triangles [][3]objCorner
+}
+
+type objParser struct {
+	name string
+	dir  string
+	open func(name string) (io.ReadCloser, error)
+	line int
+
+	positions []mgl.Vec3
+	texCoords []mgl.Vec2
+	normals   []mgl.Vec3
+
+	materials map[string]Material
+	object    string
+	material  string
+	groups    map[string]*objGroup
+	order     []*objGroup
+}
+
+func (p *objParser) parse(fields []string) error {
+	switch fields[0] {
+	case "v":
+		// x y z, optionally followed by w or by the r g b vertex colour some exporters add, which are ignored
+		values, err := parseFloats(fields[1:], 3, 7)
+		if err != nil {
+			return err
+		}
+		p.positions = append(p.positions, mgl.Vec3{values[0], values[1], values[2]})
+	case "vt":
+		values, err := parseFloats(fields[1:], 1, 3)
+		if err != nil {
+			return err
+		}
+		texCoord := mgl.Vec2{values[0], 0}
+		if len(values) > 1 {
+			texCoord[1] = values[1]
+		}
+		p.texCoords = append(p.texCoords, texCoord)
+	case "vn":
+		normal, err := parseVec3(fields[1:])
+		if err != nil {
+			return err
+		}
+		p.normals = append(p.normals, normal)
+	case "f":
+		return p.face(fields[1:])
+	case "o", "g":
+		p.object = strings.Join(fields[1:], " ")
+	case "usemtl":
+		if len(fields) < 2 {
+			return fmt.Errorf("missing material name")
+		}
+		p.material = fields[1]
+	case "mtllib":
+		for _, library := range fields[1:] {
+			if err := p.readLibrary(path.Join(p.dir, library)); err != nil {
+				return err
+			}
+		}
+	}
+	// anything else, like smoothing groups, lines and points, doesn't affect the triangles
+	return nil
+}
+
+func (p *objParser) readLibrary(name string) error {
+	file, err := p.open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	materials, err := readMTL(file, name, path.Dir(name))
+	if err != nil {
+		return err
+	}
+	for materialName, material := range materials {
+		p.materials[materialName] = material
+	}
+	return nil
+}
+
+func (p *objParser) face(fields []string) error {
+	if len(fields) < 3 {
+		return fmt.Errorf("a face needs at least 3 corners, got %d", len(fields))
+	}
+
+	corners := make([]objCorner, len(fields))
+	for i, field := range fields {
+		corner, err := p.corner(field)
+		if err != nil {
+			return err
+		}
+		corners[i] = corner
+	}
+
+	group := p.groups[p.material]
+	if group == nil {
+		group = &objGroup{name: p.object, material: p.material}
+		p.groups[p.material] = group
+		p.order = append(p.order, group)
+	}
+
+	points := make([]mgl.Vec3, len(corners))
+	for i, corner := range corners {
+		points[i] = p.positions[corner.position]
+	}
+	for _, triangle := range triangulate(points) {
+		group.triangles = append(group.triangles, [3]objCorner{corners[triangle[0]], corners[triangle[1]], corners[triangle[2]]})
+	}
+	return nil
+}
+
+// corner parses "v", "v/vt", "v//vn" or "v/vt/vn".
+func (p *objParser) corner(field string) (objCorner, error) {
+	parts := strings.Split(field, "/")
+	if len(parts) > 3 {
+		return objCorner{}, fmt.Errorf("bad face corner %q", field)
+	}
+
+	corner := objCorner{position: -1, texCoord: -1, normal: -1}
+	counts := []int{len(p.positions), len(p.texCoords), len(p.normals)}
+	targets := []*int{&corner.position, &corner.texCoord, &corner.normal}
+	for i, part := range parts {
+		if part == "" && i > 0 {
+			continue
+		}
+		index, err := resolveIndex(part, counts[i])
+		if err != nil {
+			return objCorner{}, fmt.Errorf("face corner %q: %v", field, err)
+		}
+		*targets[i] = index
+	}
+	return corner, nil
+}
+
+// resolveIndex turns a 1-based or negative (counting back from the last element) OBJ index into a 0-based one.
+func resolveIndex(s string, count int) (int, error) {
+	index, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 {
+		index += count
+	} else {
+		index--
+	}
+	if index < 0 || index >= count {
+		return 0, fmt.Errorf("index %s out of range, there are %d", s, count)
+	}
+	return index, nil
+}
+
+// model builds the meshes, sharing vertices between the triangles of a mesh that use the same corner.
+func (p *objParser) model() *Model {
+	generated := p.generateNormals()
+	// OBJ files are flat, a single node holds every mesh
+	model := &Model{
+		Materials: p.materials,
+		Nodes:     []Node{{Name: p.name, Transform: mgl.Ident4()}},
+		Roots:     []int{0},
+	}
+
+	for _, group := range p.order {
+		mesh := MeshData{Name: group.name, Material: group.material, Stride: VertexStride}
+		indices := map[objCorner]uint32{}
+
+		for _, triangle := range group.triangles {
+			for _, corner := range triangle {
+				index, ok := indices[corner]
+				if !ok {
+					index = uint32(len(mesh.Vertices) / VertexStride)
+					indices[corner] = index
+					mesh.Vertices = append(mesh.Vertices, p.vertex(corner, generated)...)
+				}
+				mesh.Indices = append(mesh.Indices, index)
+			}
+		}
+		model.Nodes[0].Meshes = append(model.Nodes[0].Meshes, len(model.Meshes))
+		model.Meshes = append(model.Meshes, mesh)
+	}
+	return model
+}
+
+func (p *objParser) vertex(corner objCorner, generated map[int]mgl.Vec3) []float32 {
+	position := p.positions[corner.position]
+
+	var normal mgl.Vec3
+	if corner.normal >= 0 {
+		normal = p.normals[corner.normal]
+	} else {
+		normal = generated[corner.position]
+	}
+
+	var texCoord mgl.Vec2
+	if corner.texCoord >= 0 {
+		texCoord = p.texCoords[corner.texCoord]
+	}
+
+	return []float32{
+		position[0], position[1], position[2],
+		normal[0], normal[1], normal[2],
+		texCoord[0], texCoord[1],
+	}
+}
+
+// generateNormals averages the normals of the faces around every position used by a corner without a normal.
+// The face normals are weighted by area, which the length of the cross product gives for free.
+func (p *objParser) generateNormals() map[int]mgl.Vec3 {
+	sums := map[int]mgl.Vec3{}
+	for _, group := range p.order {
+		for _, triangle := range group.triangles {
+			if triangle[0].normal >= 0 && triangle[1].normal >= 0 && triangle[2].normal >= 0 {
+				continue
+			}
+
+			a := p.positions[triangle[0].position]
+			b := p.positions[triangle[1].position]
+			c := p.positions[triangle[2].position]
+			faceNormal := b.Sub(a).Cross(c.Sub(a))
+			for _, corner := range triangle {
+				sums[corner.position] = sums[corner.position].Add(faceNormal)
+			}
+		}
+	}
+
+	for position, sum := range sums {
+		if sum.Len() == 0 {
+			// only degenerate faces use this position, any direction is as good as another
+			sums[position] = mgl.Vec3{0, 1, 0}
+			continue
+		}
+		sums[position] = sum.Normalize()
+	}
+	return sums
+}
+
+// triangulate splits a planar polygon into triangles by ear clipping, keeping the winding of the polygon.
+// It returns indices into points. Polygons that aren't simple enough to clip fall back to a triangle fan.
+func triangulate(points []mgl.Vec3) [][3]int {
+	if len(points) == 3 {
+		return [][3]int{{0, 1, 2}}
+	}
+
+	// Newell's method gives the polygon normal even for concave polygons
+	var normal mgl.Vec3
+	for i := range points {
+		current, next := points[i], points[(i+1)%len(points)]
+		normal[0] += (current[1] - next[1]) * (current[2] + next[2])
+		normal[1] += (current[2] - next[2]) * (current[0] + next[0])
+		normal[2] += (current[0] - next[0]) * (current[1] + next[1])
+	}
+	if normal.Len() == 0 {
+		return fan(len(points))
+	}
+
+	// project onto the plane of the two axes the normal points least along
+	x, y := 0, 1
+	switch {
+	case abs(normal[0]) >= abs(normal[1]) && abs(normal[0]) >= abs(normal[2]):
+		x, y = 1, 2
+	case abs(normal[1]) >= abs(normal[2]):
+		x, y = 2, 0
+	}
+	flat := make([]mgl.Vec2, len(points))
+	for i, point := range points {
+		flat[i] = mgl.Vec2{point[x], point[y]}
+	}
+
+	// the projection may mirror the polygon, orientation makes convex corners positive either way
+	var area float32
+	for i := range flat {
+		area += cross2(flat[i], flat[(i+1)%len(flat)])
+	}
+	orientation := float32(1)
+	if area < 0 {
+		orientation = -1
+	}
+
+	remaining := make([]int, len(points))
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	var triangles [][3]int
+	for len(remaining) > 3 {
+		ear := -1
+		for i := range remaining {
+			prev := remaining[(i+len(remaining)-1)%len(remaining)]
+			current := remaining[i]
+			next := remaining[(i+1)%len(remaining)]
+			if isEar(flat, remaining, prev, current, next, orientation) {
+				ear = i
+				triangles = append(triangles, [3]int{prev, current, next})
+				break
+			}
+		}
+		if ear < 0 {
+			// self-intersecting or degenerate, a fan is better than losing the face
+			return fan(len(points))
+		}
+		remaining = append(remaining[:ear], remaining[ear+1:]...)
+	}
+	return append(triangles, [3]int{remaining[0], remaining[1], remaining[2]})
+}
+
+func isEar(flat []mgl.Vec2, remaining []int, prev, current, next int, orientation float32) bool {
+	a, b, c := flat[prev], flat[current], flat[next]
+	if cross2(b.Sub(a), c.Sub(b))*orientation <= 0 {
+		return false
+	}
+	for _, other := range remaining {
+		if other == prev || other == current || other == next {
+			continue
+		}
+		if insideTriangle(flat[other], a, b, c, orientation) {
+			return false
+		}
+	}
+	return true
+}
+
+func insideTriangle(p, a, b, c mgl.Vec2, orientation float32) bool {
+	return cross2(b.Sub(a), p.Sub(a))*orientation >= 0 &&
+		cross2(c.Sub(b), p.Sub(b))*orientation >= 0 &&
+		cross2(a.Sub(c), p.Sub(c))*orientation >= 0
+}
+
+func fan(n int) [][3]int {
+	triangles := make([][3]int, 0, n-2)
+	for i := 1; i < n-1; i++ {
+		triangles = append(triangles, [3]int{0, i, i + 1})
+	}
+	return triangles
+}
+
+func cross2(a, b mgl.Vec2) float32 {
+	return a[0]*b[1] - a[1]*b[0]
+}
+
+func abs(f float32) float32 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+func stripComment(line string) string {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// parseFloats parses between min and max numbers.
+func parseFloats(fields []string, min int, max int) ([]float32, error) {
+	if len(fields) < min || len(fields) > max {
+		return nil, fmt.Errorf("expected %d to %d numbers, got %d", min, max, len(fields))
+	}
+	values := make([]float32, len(fields))
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field, 32)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = float32(value)
+	}
+	return values, nil
+}
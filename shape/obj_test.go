@@ -0,0 +1,287 @@
+package shape
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+func noLibraries(name string) (io.ReadCloser, error) {
+	return nil, io.ErrUnexpectedEOF
+}
+
+func TestReadOBJVertexColours(t *testing.T) {
+	const obj = `
+v 0 0 0 1 0 0
+v 1 0 0 0 1 0
+v 0 1 0 1.0 0 0 1
+f 1 2 3
+`
+	model, err := ReadOBJ(strings.NewReader(obj), "colours.obj", noLibraries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(model.Meshes) != 1 || len(model.Meshes[0].Indices) != 3 {
+		t.Fatalf("got %d meshes, want one triangle", len(model.Meshes))
+	}
+	// the colours don't end up in the positions
+	vertices := model.Meshes[0].Vertices
+	if vertices[VertexStride] != 1 || vertices[VertexStride+1] != 0 || vertices[2*VertexStride+1] != 1 {
+		t.Errorf("wrong positions %v", vertices)
+	}
+}
+
+func TestReadOBJTooManyValues(t *testing.T) {
+	if _, err := ReadOBJ(strings.NewReader("v 0 0 0 0 0 0 0 0\n"), "bad.obj", noLibraries); err == nil {
+		t.Error("no error for a vertex with 8 values")
+	}
+}
+
+// readOBJ parses obj, with libraries holding the material libraries by asset name.
+func readOBJ(t *testing.T, obj string, libraries map[string]string) *Model {
+	t.Helper()
+	open := func(name string) (io.ReadCloser, error) {
+		library, ok := libraries[name]
+		if !ok {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return ioutil.NopCloser(strings.NewReader(library)), nil
+	}
+	model, err := ReadOBJ(strings.NewReader(obj), "models/test.obj", open)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+// meshTriangles returns the corner positions of every triangle of a mesh.
+func meshTriangles(mesh MeshData) [][3]mgl.Vec3 {
+	position := func(index uint32) mgl.Vec3 {
+		v := mesh.Vertices[int(index)*mesh.Stride:]
+		return mgl.Vec3{v[0], v[1], v[2]}
+	}
+	var triangles [][3]mgl.Vec3
+	for i := 0; i+2 < len(mesh.Indices); i += 3 {
+		triangles = append(triangles, [3]mgl.Vec3{position(mesh.Indices[i]), position(mesh.Indices[i+1]), position(mesh.Indices[i+2])})
+	}
+	return triangles
+}
+
+func TestReadOBJTriangulates(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  string
+		// normal is the side the polygon faces, area its area
+		normal mgl.Vec3
+		area   float32
+	}{
+		{"triangle", "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3", mgl.Vec3{0, 0, 1}, 0.5},
+		{"quad", "v 0 0 0\nv 2 0 0\nv 2 1 0\nv 0 1 0\nf 1 2 3 4", mgl.Vec3{0, 0, 1}, 2},
+		{"clockwise quad", "v 0 0 0\nv 0 1 0\nv 2 1 0\nv 2 0 0\nf 1 2 3 4", mgl.Vec3{0, 0, -1}, 2},
+		// an L, the fan from the first corner would cover the notch
+		{"concave L", "v 0 0 0\nv 2 0 0\nv 2 1 0\nv 1 1 0\nv 1 2 0\nv 0 2 0\nf 2 3 4 5 6 1", mgl.Vec3{0, 0, 1}, 3},
+		// an arrow head pointing up, the fan from its right corner would cover the notch
+		{"concave arrow", "v 0 1 0\nv -2 0 0\nv 0 3 0\nv 2 0 0\nf 4 3 2 1", mgl.Vec3{0, 0, 1}, 4},
+		// a hexagon lying in the XZ plane facing up, in another projection than the ones above
+		{"hexagon", "v 1 0 0\nv 0.5 0 -0.866\nv -0.5 0 -0.866\nv -1 0 0\nv -0.5 0 0.866\nv 0.5 0 0.866\nf 1 2 3 4 5 6", mgl.Vec3{0, 1, 0}, 2.598},
+		// a star with five points, concave at every other corner
+		{"star", "v 0 3 0\nv -1 1 0\nv -3 1 0\nv -1.5 -0.5 0\nv -2 -3 0\nv 0 -1.5 0\nv 2 -3 0\nv 1.5 -0.5 0\nv 3 1 0\nv 1 1 0\nf 1 2 3 4 5 6 7 8 9 10", mgl.Vec3{0, 0, 1}, 14.5},
+	}
+	for _, test := range tests {
+		model := readOBJ(t, test.obj, nil)
+		corners := strings.Count(test.obj, "v ")
+		triangles := meshTriangles(model.Meshes[0])
+		if len(triangles) != corners-2 {
+			t.Errorf("%s: got %d triangles, want %d", test.name, len(triangles), corners-2)
+			continue
+		}
+
+		// every triangle keeps the winding of the polygon, and together they cover exactly its area
+		var area float32
+		for _, triangle := range triangles {
+			cross := triangle[1].Sub(triangle[0]).Cross(triangle[2].Sub(triangle[0]))
+			if cross.Dot(test.normal) <= 0 {
+				t.Errorf("%s: triangle %v is turned around or degenerate", test.name, triangle)
+			}
+			area += cross.Len() / 2
+		}
+		if abs(area-test.area) > 1e-3 {
+			t.Errorf("%s: the triangles cover %g, want %g", test.name, area, test.area)
+		}
+	}
+}
+
+func TestReadOBJNegativeIndices(t *testing.T) {
+	const vertices = `
+v 0 0 0
+v 1 0 0
+v 0 1 0
+vt 0 0
+vt 1 0
+vt 0 1
+vn 0 0 1
+`
+	want := readOBJ(t, vertices+"f 1/1/1 2/2/1 3/3/1", nil).Meshes[0]
+	tests := []struct {
+		name string
+		face string
+	}{
+		{"negative", "f -3/-3/-1 -2/-2/-1 -1/-1/-1"},
+		{"mixed", "f 1/-3/1 -2/2/-1 3/-1/1"},
+	}
+	for _, test := range tests {
+		got := readOBJ(t, vertices+test.face, nil).Meshes[0]
+		if !equalFloats(got.Vertices, want.Vertices) || !equalIndices(got.Indices, want.Indices) {
+			t.Errorf("%s: got %v %v, want %v %v", test.name, got.Vertices, got.Indices, want.Vertices, want.Indices)
+		}
+	}
+
+	// relative indices count back from the vertices read so far, not from the end of the file
+	model := readOBJ(t, "v 0 0 0\nv 1 0 0\nv 0 1 0\nf -3 -2 -1\nv 5 5 5\nf -4 -3 -2", nil)
+	if triangles := meshTriangles(model.Meshes[0]); len(triangles) != 2 || triangles[0] != triangles[1] {
+		t.Errorf("got triangles %v, want the same one twice", triangles)
+	}
+
+	for _, face := range []string{"f -4 -2 -1", "f 0 1 2", "f 1 2 4", "f 1/-4 2 3", "f 1//-2 2 3"} {
+		if _, err := ReadOBJ(strings.NewReader(vertices+face), "bad.obj", noLibraries); err == nil {
+			t.Errorf("%q: no error for an index out of range", face)
+		}
+	}
+}
+
+func TestReadOBJGeneratesNormals(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  string
+		// normals are the expected normals by position
+		normals map[mgl.Vec3]mgl.Vec3
+	}{
+		{
+			name:    "flat quad",
+			obj:     "v 0 0 0\nv 1 0 0\nv 1 0 -1\nv 0 0 -1\nf 1 2 3 4",
+			normals: map[mgl.Vec3]mgl.Vec3{{0, 0, 0}: {0, 1, 0}, {1, 0, 0}: {0, 1, 0}, {1, 0, -1}: {0, 1, 0}, {0, 0, -1}: {0, 1, 0}},
+		},
+		{
+			// two faces at a right angle share an edge, its corners average the faces
+			name: "fold",
+			obj:  "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nv 0 0 -1\nv 1 0 -1\nf 1 2 3 4\nf 1 2 6 5",
+			normals: map[mgl.Vec3]mgl.Vec3{
+				{0, 0, 0}: mgl.Vec3{0, 1, 1}.Normalize(), {1, 0, 0}: mgl.Vec3{0, 1, 1}.Normalize(),
+				{1, 1, 0}: {0, 0, 1}, {0, 0, -1}: {0, 1, 0},
+			},
+		},
+		{
+			// the corners with a normal keep it, the others get one from the face
+			name:    "partly given",
+			obj:     "v 0 0 0\nv 1 0 0\nv 0 1 0\nvn 1 0 0\nf 1//1 2 3",
+			normals: map[mgl.Vec3]mgl.Vec3{{0, 0, 0}: {1, 0, 0}, {1, 0, 0}: {0, 0, 1}, {0, 1, 0}: {0, 0, 1}},
+		},
+	}
+	for _, test := range tests {
+		mesh := readOBJ(t, test.obj, nil).Meshes[0]
+		for i := 0; i < len(mesh.Vertices); i += mesh.Stride {
+			position := mgl.Vec3{mesh.Vertices[i], mesh.Vertices[i+1], mesh.Vertices[i+2]}
+			normal := mgl.Vec3{mesh.Vertices[i+3], mesh.Vertices[i+4], mesh.Vertices[i+5]}
+			if want, ok := test.normals[position]; ok && normal.Sub(want).Len() > 1e-5 {
+				t.Errorf("%s: normal at %v is %v, want %v", test.name, position, normal, want)
+			}
+		}
+	}
+}
+
+func TestReadOBJMaterials(t *testing.T) {
+	const mtl = `
+# two materials
+newmtl wood
+Kd 0.6 0.4 0.2
+Ks 0.1 0.1 0.1
+Ns 16
+map_Kd textures/wood.png
+map_Bump -bm 0.5 textures\wood_normal.png
+
+newmtl metal
+Ks 1 1 1
+map_Ks metal_specular.png
+`
+	const obj = `
+mtllib materials.mtl
+v 0 0 0
+v 1 0 0
+v 0 1 0
+v 1 1 0
+f 1 2 3
+usemtl wood
+f 1 2 3
+f 2 4 3
+usemtl metal
+f 1 2 4
+usemtl wood
+f 1 2 4
+`
+	model := readOBJ(t, obj, map[string]string{"models/materials.mtl": mtl})
+
+	// one mesh per material, in the order they first appear, faces without a material get their own
+	tests := []struct {
+		material  string
+		triangles int
+	}{
+		{"", 1},
+		{"wood", 3},
+		{"metal", 1},
+	}
+	if len(model.Meshes) != len(tests) {
+		t.Fatalf("got %d meshes, want %d", len(model.Meshes), len(tests))
+	}
+	for i, test := range tests {
+		mesh := model.Meshes[i]
+		if mesh.Material != test.material || len(mesh.Indices) != test.triangles*3 {
+			t.Errorf("mesh %d has material %q and %d triangles, want %q and %d",
+				i, mesh.Material, len(mesh.Indices)/3, test.material, test.triangles)
+		}
+	}
+	if len(model.Nodes) != 1 || len(model.Nodes[0].Meshes) != len(tests) {
+		t.Errorf("the node doesn't draw every mesh: %+v", model.Nodes)
+	}
+
+	// texture maps are relative to the library
+	wood := model.Materials["wood"]
+	if wood.Diffuse != (mgl.Vec3{0.6, 0.4, 0.2}) || wood.Shininess != 16 ||
+		wood.DiffuseMap != "models/textures/wood.png" || wood.NormalMap != "models/textures/wood_normal.png" {
+		t.Errorf("got wood %+v", wood)
+	}
+	metal := model.Materials["metal"]
+	if metal.Diffuse != (mgl.Vec3{1, 1, 1}) || metal.Specular != (mgl.Vec3{1, 1, 1}) || metal.SpecularMap != "models/metal_specular.png" {
+		t.Errorf("got metal %+v", metal)
+	}
+
+	if _, err := ReadOBJ(strings.NewReader("mtllib missing.mtl\n"), "bad.obj", noLibraries); err == nil {
+		t.Error("no error for a missing material library")
+	}
+}
+
+func equalFloats(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalIndices(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
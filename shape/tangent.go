@@ -0,0 +1,149 @@
+package shape
+
+import (
+	"math"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// GenerateTangents returns mesh in the TangentStride layout, with tangents computed from the texture coordinates.
+//
+// It follows the conventions of MikkTSpace, the tangent space normal maps are usually baked in: the tangent of a
+// vertex is the angle weighted average of the tangents of the triangles around it, made perpendicular to the
+// vertex normal, and w holds the handedness of the bitangent. A vertex shared by triangles with mirrored texture
+// coordinates is split in two, so both sides keep their own handedness.
+func GenerateTangents(mesh MeshData) MeshData {
+	stride := mesh.Stride
+	vertexCount := len(mesh.Vertices) / stride
+
+	position := func(index uint32) mgl.Vec3 {
+		v := mesh.Vertices[int(index)*stride:]
+		return mgl.Vec3{v[0], v[1], v[2]}
+	}
+	normal := func(index uint32) mgl.Vec3 {
+		v := mesh.Vertices[int(index)*stride:]
+		return mgl.Vec3{v[3], v[4], v[5]}
+	}
+	uv := func(index uint32) mgl.Vec2 {
+		v := mesh.Vertices[int(index)*stride:]
+		return mgl.Vec2{v[6], v[7]}
+	}
+
+	// sums of the tangents around every vertex, separately for both handednesses
+	sums := make([][2]mgl.Vec3, vertexCount)
+	handedness := make([]int, len(mesh.Indices)/3)
+
+	for triangle := range handedness {
+		corners := mesh.Indices[triangle*3 : triangle*3+3]
+		p0, p1, p2 := position(corners[0]), position(corners[1]), position(corners[2])
+		uv0, uv1, uv2 := uv(corners[0]), uv(corners[1]), uv(corners[2])
+
+		edge1, edge2 := p1.Sub(p0), p2.Sub(p0)
+		du1, dv1 := uv1[0]-uv0[0], uv1[1]-uv0[1]
+		du2, dv2 := uv2[0]-uv0[0], uv2[1]-uv0[1]
+
+		det := du1*dv2 - du2*dv1
+		if abs(det) < 1e-12 {
+			// no usable texture coordinates, the triangle has no say in the tangents
+			continue
+		}
+		tangent := edge1.Mul(dv2).Sub(edge2.Mul(dv1)).Mul(1 / det)
+		bitangent := edge2.Mul(du1).Sub(edge1.Mul(du2)).Mul(1 / det)
+		// judge the handedness by the vertex normals rather than the winding, which isn't always consistent
+		faceNormal := normal(corners[0]).Add(normal(corners[1])).Add(normal(corners[2]))
+		if faceNormal.Len() == 0 {
+			faceNormal = edge1.Cross(edge2)
+		}
+		if faceNormal.Cross(tangent).Dot(bitangent) < 0 {
+			handedness[triangle] = 1
+		}
+
+		points := [3]mgl.Vec3{p0, p1, p2}
+		for i, corner := range corners {
+			n := normal(corner)
+			projected := tangent.Sub(n.Mul(n.Dot(tangent)))
+			if projected.Len() == 0 {
+				continue
+			}
+			angle := cornerAngle(points[i], points[(i+1)%3], points[(i+2)%3])
+			sums[corner][handedness[triangle]] = sums[corner][handedness[triangle]].Add(projected.Normalize().Mul(angle))
+		}
+	}
+
+	// every vertex keeps its index for the handedness it is used with first, a second one gets a copy
+	result := MeshData{Name: mesh.Name, Material: mesh.Material, Stride: TangentStride, Indices: make([]uint32, len(mesh.Indices))}
+	result.Vertices = make([]float32, 0, vertexCount*TangentStride)
+	for i := 0; i < vertexCount; i++ {
+		result.Vertices = appendTangentVertex(result.Vertices, mesh.Vertices[i*stride:i*stride+VertexStride], sums[i][0], 0)
+	}
+
+	firstHandedness := make([]int, vertexCount)
+	for i := range firstHandedness {
+		firstHandedness[i] = -1
+	}
+	copies := map[uint32]uint32{}
+	for i, corner := range mesh.Indices {
+		h := handedness[i/3]
+		switch {
+		case firstHandedness[corner] < 0 || firstHandedness[corner] == h:
+			firstHandedness[corner] = h
+			result.Indices[i] = corner
+			setTangent(result.Vertices[int(corner)*TangentStride:], sums[corner][h], h)
+		default:
+			index, ok := copies[corner]
+			if !ok {
+				index = uint32(len(result.Vertices) / TangentStride)
+				copies[corner] = index
+				result.Vertices = appendTangentVertex(result.Vertices, mesh.Vertices[int(corner)*stride:int(corner)*stride+VertexStride], sums[corner][h], h)
+			}
+			result.Indices[i] = index
+		}
+	}
+	return result
+}
+
+func appendTangentVertex(vertices []float32, vertex []float32, tangentSum mgl.Vec3, handedness int) []float32 {
+	vertices = append(vertices, vertex...)
+	vertices = append(vertices, 0, 0, 0, 0)
+	setTangent(vertices[len(vertices)-TangentStride:], tangentSum, handedness)
+	return vertices
+}
+
+// setTangent stores the normalized tangent and its handedness, 0 for right handed and 1 for mirrored, in a vertex.
+func setTangent(vertex []float32, tangentSum mgl.Vec3, handedness int) {
+	normal := mgl.Vec3{vertex[3], vertex[4], vertex[5]}
+	tangent := tangentSum.Sub(normal.Mul(normal.Dot(tangentSum)))
+	if tangent.Len() < 1e-9 {
+		tangent = perpendicular(normal)
+	}
+	tangent = tangent.Normalize()
+
+	w := float32(1)
+	if handedness == 1 {
+		w = -1
+	}
+	copy(vertex[8:12], []float32{tangent[0], tangent[1], tangent[2], w})
+}
+
+// cornerAngle returns the angle of a triangle at corner, between the edges to the other two corners.
+func cornerAngle(corner, next, prev mgl.Vec3) float32 {
+	a, b := next.Sub(corner), prev.Sub(corner)
+	if a.Len() == 0 || b.Len() == 0 {
+		return 0
+	}
+	cos := mgl.Clamp(a.Normalize().Dot(b.Normalize()), -1, 1)
+	return float32(math.Acos(float64(cos)))
+}
+
+// perpendicular returns some unit vector perpendicular to v.
+func perpendicular(v mgl.Vec3) mgl.Vec3 {
+	axis := mgl.Vec3{1, 0, 0}
+	if abs(v[0]) > 0.9 {
+		axis = mgl.Vec3{0, 1, 0}
+	}
+	p := axis.Sub(v.Mul(v.Dot(axis)))
+	if p.Len() == 0 {
+		return axis
+	}
+	return p.Normalize()
+}
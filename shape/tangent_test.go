@@ -0,0 +1,185 @@
+package shape
+
+import (
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// quad returns a unit square in the XY plane with the given texture coordinates at its corners, counter-clockwise
+// from the origin, and normal at every corner.
+func quad(uvs [4]mgl.Vec2, normal mgl.Vec3) MeshData {
+	positions := [4]mgl.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}}
+	mesh := MeshData{Stride: VertexStride, Indices: []uint32{0, 1, 2, 0, 2, 3}}
+	for i, p := range positions {
+		mesh.Vertices = append(mesh.Vertices, p[0], p[1], p[2], normal[0], normal[1], normal[2], uvs[i][0], uvs[i][1])
+	}
+	return mesh
+}
+
+// tangentAt returns the normal, tangent and handedness of a vertex in the TangentStride layout.
+func tangentAt(mesh MeshData, index uint32) (normal, tangent mgl.Vec3, w float32) {
+	v := mesh.Vertices[int(index)*TangentStride:]
+	return mgl.Vec3{v[3], v[4], v[5]}, mgl.Vec3{v[8], v[9], v[10]}, v[11]
+}
+
+// checkTangentSpace checks every vertex has a unit tangent across its normal and a handedness of ±1. With followUV
+// it checks as well that the tangent points along increasing u in every triangle, and the bitangent the shader
+// builds, cross(normal, tangent) * w, along increasing v.
+func checkTangentSpace(t *testing.T, name string, mesh MeshData, followUV bool) {
+	t.Helper()
+	for i := 0; i < len(mesh.Vertices)/TangentStride; i++ {
+		normal, tangent, w := tangentAt(mesh, uint32(i))
+		if length := tangent.Len(); length < 0.999 || length > 1.001 {
+			t.Errorf("%s: tangent %d %v isn't a unit vector", name, i, tangent)
+		}
+		if dot := tangent.Dot(normal); dot > 1e-5 || dot < -1e-5 {
+			t.Errorf("%s: tangent %d %v isn't perpendicular to normal %v", name, i, tangent, normal)
+		}
+		if w != 1 && w != -1 {
+			t.Errorf("%s: handedness %d is %g, want ±1", name, i, w)
+		}
+	}
+	if !followUV {
+		return
+	}
+
+	for triangle := 0; triangle+2 < len(mesh.Indices); triangle += 3 {
+		corners := mesh.Indices[triangle : triangle+3]
+		var p [3]mgl.Vec3
+		var uv [3]mgl.Vec2
+		for i, corner := range corners {
+			v := mesh.Vertices[int(corner)*TangentStride:]
+			p[i], uv[i] = mgl.Vec3{v[0], v[1], v[2]}, mgl.Vec2{v[6], v[7]}
+		}
+		edge1, edge2 := p[1].Sub(p[0]), p[2].Sub(p[0])
+		du1, dv1, du2, dv2 := uv[1][0]-uv[0][0], uv[1][1]-uv[0][1], uv[2][0]-uv[0][0], uv[2][1]-uv[0][1]
+		det := du1*dv2 - du2*dv1
+		if abs(det) < 1e-9 {
+			continue
+		}
+		alongU := edge1.Mul(dv2).Sub(edge2.Mul(dv1)).Mul(1 / det)
+		alongV := edge2.Mul(du1).Sub(edge1.Mul(du2)).Mul(1 / det)
+		for _, corner := range corners {
+			normal, tangent, w := tangentAt(mesh, corner)
+			if tangent.Dot(alongU) <= 0 {
+				t.Errorf("%s: tangent of vertex %d %v points against u %v", name, corner, tangent, alongU)
+			}
+			if bitangent := normal.Cross(tangent).Mul(w); bitangent.Dot(alongV) <= 0 {
+				t.Errorf("%s: bitangent of vertex %d %v points against v %v", name, corner, bitangent, alongV)
+			}
+		}
+	}
+}
+
+func TestGenerateTangentsHandedness(t *testing.T) {
+	tests := []struct {
+		name    string
+		uvs     [4]mgl.Vec2
+		tangent mgl.Vec3
+		w       float32
+	}{
+		{"plain", [4]mgl.Vec2{{0, 0}, {1, 0}, {1, 1}, {0, 1}}, mgl.Vec3{1, 0, 0}, 1},
+		{"u mirrored", [4]mgl.Vec2{{1, 0}, {0, 0}, {0, 1}, {1, 1}}, mgl.Vec3{-1, 0, 0}, -1},
+		{"v mirrored", [4]mgl.Vec2{{0, 1}, {1, 1}, {1, 0}, {0, 0}}, mgl.Vec3{1, 0, 0}, -1},
+		{"turned half way", [4]mgl.Vec2{{1, 1}, {0, 1}, {0, 0}, {1, 0}}, mgl.Vec3{-1, 0, 0}, 1},
+		{"u and v swapped", [4]mgl.Vec2{{0, 0}, {0, 1}, {1, 1}, {1, 0}}, mgl.Vec3{0, 1, 0}, -1},
+		{"scaled", [4]mgl.Vec2{{0, 0}, {4, 0}, {4, 0.5}, {0, 0.5}}, mgl.Vec3{1, 0, 0}, 1},
+	}
+	for _, test := range tests {
+		mesh := GenerateTangents(quad(test.uvs, mgl.Vec3{0, 0, 1}))
+		if mesh.Stride != TangentStride || len(mesh.Vertices) != 4*TangentStride {
+			t.Errorf("%s: got stride %d and %d floats, want 4 vertices of %d", test.name, mesh.Stride, len(mesh.Vertices), TangentStride)
+			continue
+		}
+		for i := uint32(0); i < 4; i++ {
+			if _, tangent, w := tangentAt(mesh, i); tangent.Sub(test.tangent).Len() > 1e-5 || w != test.w {
+				t.Errorf("%s: vertex %d has tangent %v %g, want %v %g", test.name, i, tangent, w, test.tangent, test.w)
+			}
+		}
+		checkTangentSpace(t, test.name, mesh, true)
+	}
+}
+
+func TestGenerateTangentsOrthogonal(t *testing.T) {
+	uvs := [4]mgl.Vec2{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	tests := []struct {
+		name     string
+		mesh     MeshData
+		followUV bool
+	}{
+		// smooth normals lean away from the face, the tangent follows them
+		{"tilted normals", quad(uvs, mgl.Vec3{0.6, 0, 0.8}), true},
+		// when u runs along the normal, or there are no texture coordinates, any perpendicular direction will do
+		{"normals along u", quad(uvs, mgl.Vec3{1, 0, 0}), false},
+		{"no texture coordinates", quad([4]mgl.Vec2{}, mgl.Vec3{0, 0, 1}), false},
+		{"sphere", Sphere(1, 16, 8), true},
+		{"cube", cube(), true},
+	}
+	for _, test := range tests {
+		mesh := test.mesh
+		if mesh.Stride == TangentStride {
+			// the generated meshes come with tangents, work from the plain layout again
+			mesh = withoutTangents(mesh)
+		}
+		result := GenerateTangents(mesh)
+		checkTangentSpace(t, test.name, result, test.followUV)
+
+		// the other floats are copied as they are
+		for i := 0; i < len(mesh.Vertices)/VertexStride; i++ {
+			if !equalFloats(result.Vertices[i*TangentStride:i*TangentStride+VertexStride], mesh.Vertices[i*VertexStride:(i+1)*VertexStride]) {
+				t.Errorf("%s: vertex %d changed", test.name, i)
+				break
+			}
+		}
+	}
+}
+
+func TestGenerateTangentsSplitsMirroredVertices(t *testing.T) {
+	// two squares side by side sharing the edge x = 1, the right one with u mirrored like a symmetric model
+	mesh := MeshData{Stride: VertexStride, Indices: []uint32{0, 1, 2, 0, 2, 3, 1, 4, 5, 1, 5, 2}}
+	for _, v := range [][5]float32{
+		{0, 0, 0, 0, 0}, {1, 0, 0, 1, 0}, {1, 1, 0, 1, 1}, {0, 1, 0, 0, 1},
+		{2, 0, 0, 0, 0}, {2, 1, 0, 0, 1},
+	} {
+		mesh.Vertices = append(mesh.Vertices, v[0], v[1], v[2], 0, 0, 1, v[3], v[4])
+	}
+
+	result := GenerateTangents(mesh)
+	// the two vertices on the seam are used with both handednesses and get a copy each
+	if count := len(result.Vertices) / TangentStride; count != 8 {
+		t.Fatalf("got %d vertices, want 8", count)
+	}
+	checkTangentSpace(t, "seam", result, true)
+
+	for triangle := 0; triangle < len(result.Indices); triangle += 3 {
+		want := float32(1)
+		if triangle >= 6 {
+			want = -1
+		}
+		for _, corner := range result.Indices[triangle : triangle+3] {
+			if _, _, w := tangentAt(result, corner); w != want {
+				t.Errorf("triangle %d uses vertex %d with handedness %g, want %g", triangle/3, corner, w, want)
+			}
+		}
+	}
+	// the copies sit at the same place as the originals
+	for _, original := range []uint32{1, 2} {
+		copied := false
+		for i := 6; i < 8; i++ {
+			copied = copied || equalFloats(result.Vertices[i*TangentStride:i*TangentStride+VertexStride], mesh.Vertices[original*VertexStride:(original+1)*VertexStride])
+		}
+		if !copied {
+			t.Errorf("vertex %d has no copy", original)
+		}
+	}
+}
+
+// withoutTangents returns mesh in the VertexStride layout.
+func withoutTangents(mesh MeshData) MeshData {
+	plain := MeshData{Stride: VertexStride, Indices: mesh.Indices}
+	for i := 0; i+TangentStride <= len(mesh.Vertices); i += TangentStride {
+		plain.Vertices = append(plain.Vertices, mesh.Vertices[i:i+VertexStride]...)
+	}
+	return plain
+}
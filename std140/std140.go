@@ -0,0 +1,123 @@
+// Package std140 packs uniform block data following the std140 layout rules, without needing an OpenGL context.
+package std140
+
+import (
+	"encoding/binary"
+	"math"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// Buffer packs values into a byte slice following the std140 layout rules of uniform blocks.
+// Values have to be written in the order they are declared in the block. The encoder inserts
+// the padding std140 requires in front of each value, so the result can be uploaded as is.
+type Buffer struct {
+	buf []byte
+}
+
+// Bytes returns the packed data.
+func (b *Buffer) Bytes() []byte {
+	return b.buf
+}
+
+// Len returns the number of bytes packed so far.
+func (b *Buffer) Len() int {
+	return len(b.buf)
+}
+
+// Reset empties the encoder so it can be reused for the next frame without allocating.
+func (b *Buffer) Reset() {
+	b.buf = b.buf[:0]
+}
+
+// Align pads the data with zeros up to the next multiple of n bytes.
+func (b *Buffer) Align(n int) {
+	for len(b.buf)%n != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func (b *Buffer) putUint32(v uint32) {
+	var word [4]byte
+	binary.LittleEndian.PutUint32(word[:], v)
+	b.buf = append(b.buf, word[:]...)
+}
+
+func (b *Buffer) putFloats(values ...float32) {
+	for _, v := range values {
+		b.putUint32(math.Float32bits(v))
+	}
+}
+
+// Float packs a float, aligned to 4 bytes.
+func (b *Buffer) Float(v float32) {
+	b.Align(4)
+	b.putFloats(v)
+}
+
+// Int packs an int, aligned to 4 bytes.
+func (b *Buffer) Int(v int32) {
+	b.Align(4)
+	b.putUint32(uint32(v))
+}
+
+// Bool packs a bool, which takes up 4 bytes like an int.
+func (b *Buffer) Bool(v bool) {
+	if v {
+		b.Int(1)
+	} else {
+		b.Int(0)
+	}
+}
+
+// Vec2 packs a vec2, aligned to 8 bytes.
+func (b *Buffer) Vec2(v mgl.Vec2) {
+	b.Align(8)
+	b.putFloats(v[:]...)
+}
+
+// Vec3 packs a vec3, aligned to 16 bytes. A float following it fills up the remaining 4 bytes.
+func (b *Buffer) Vec3(v mgl.Vec3) {
+	b.Align(16)
+	b.putFloats(v[:]...)
+}
+
+// Vec4 packs a vec4, aligned to 16 bytes.
+func (b *Buffer) Vec4(v mgl.Vec4) {
+	b.Align(16)
+	b.putFloats(v[:]...)
+}
+
+// Mat3 packs a mat3 as three columns, each padded to the size of a vec4.
+func (b *Buffer) Mat3(m mgl.Mat3) {
+	for column := 0; column < 3; column++ {
+		b.Align(16)
+		b.putFloats(m[column*3 : column*3+3]...)
+	}
+	b.Align(16)
+}
+
+// Mat4 packs a mat4 as four vec4 columns.
+func (b *Buffer) Mat4(m mgl.Mat4) {
+	b.Align(16)
+	b.putFloats(m[:]...)
+}
+
+// BeginStruct starts a struct, including a struct that is an element of an array. Structs are aligned to 16 bytes.
+func (b *Buffer) BeginStruct() {
+	b.Align(16)
+}
+
+// EndStruct ends a struct, padding its size to a multiple of 16 bytes.
+func (b *Buffer) EndStruct() {
+	b.Align(16)
+}
+
+// FloatArray packs an array of floats. Every element of a std140 array takes up 16 bytes.
+func (b *Buffer) FloatArray(values []float32) {
+	for _, v := range values {
+		b.Align(16)
+		b.putFloats(v)
+	}
+	b.Align(16)
+}
@@ -0,0 +1,161 @@
+package std140
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// floatAt reads the float packed at offset.
+func floatAt(data []byte, offset int) float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(data[offset:]))
+}
+
+func TestLayout(t *testing.T) {
+	tests := []struct {
+		name string
+		pack func(b *Buffer)
+		// floats maps the offsets of the packed values to the values expected there
+		floats map[int]float32
+		size   int
+	}{
+		{
+			name: "float after vec3 fills its last 4 bytes",
+			pack: func(b *Buffer) {
+				b.Vec3(mgl.Vec3{1, 2, 3})
+				b.Float(4)
+			},
+			floats: map[int]float32{0: 1, 4: 2, 8: 3, 12: 4},
+			size:   16,
+		},
+		{
+			name: "vec3 after vec3 starts on the next 16 bytes",
+			pack: func(b *Buffer) {
+				b.Vec3(mgl.Vec3{1, 2, 3})
+				b.Vec3(mgl.Vec3{4, 5, 6})
+			},
+			floats: map[int]float32{0: 1, 16: 4, 20: 5, 24: 6},
+			size:   28,
+		},
+		{
+			name: "vec2 aligned to 8",
+			pack: func(b *Buffer) {
+				b.Float(1)
+				b.Vec2(mgl.Vec2{2, 3})
+			},
+			floats: map[int]float32{0: 1, 8: 2, 12: 3},
+			size:   16,
+		},
+		{
+			name: "vec4 aligned to 16",
+			pack: func(b *Buffer) {
+				b.Float(1)
+				b.Vec4(mgl.Vec4{2, 3, 4, 5})
+			},
+			floats: map[int]float32{0: 1, 16: 2, 28: 5},
+			size:   32,
+		},
+		{
+			name: "mat3 columns padded to vec4",
+			pack: func(b *Buffer) {
+				b.Float(1)
+				b.Mat3(mgl.Mat3{2, 3, 4, 5, 6, 7, 8, 9, 10})
+				b.Float(11)
+			},
+			floats: map[int]float32{0: 1, 16: 2, 24: 4, 28: 0, 32: 5, 48: 8, 56: 10, 60: 0, 64: 11},
+			size:   68,
+		},
+		{
+			name: "mat4 aligned to 16",
+			pack: func(b *Buffer) {
+				b.Int(1)
+				b.Mat4(mgl.Ident4())
+			},
+			floats: map[int]float32{16: 1, 20: 0, 36: 1, 76: 1},
+			size:   80,
+		},
+		{
+			name: "array elements take 16 bytes each",
+			pack: func(b *Buffer) {
+				b.FloatArray([]float32{1, 2, 3})
+				b.Float(4)
+			},
+			floats: map[int]float32{0: 1, 16: 2, 32: 3, 48: 4},
+			size:   52,
+		},
+		{
+			name: "struct starts and ends on 16 bytes",
+			pack: func(b *Buffer) {
+				b.Float(1)
+				b.BeginStruct()
+				b.Float(2)
+				b.EndStruct()
+				b.Float(3)
+			},
+			floats: map[int]float32{0: 1, 16: 2, 32: 3},
+			size:   36,
+		},
+		{
+			name: "array of structs",
+			pack: func(b *Buffer) {
+				for i := 0; i < 2; i++ {
+					b.BeginStruct()
+					b.Vec3(mgl.Vec3{float32(i), 0, 0})
+					b.Float(5)
+					b.Vec2(mgl.Vec2{6, 7})
+					b.EndStruct()
+				}
+			},
+			floats: map[int]float32{0: 0, 12: 5, 16: 6, 32: 1, 44: 5, 48: 6, 52: 7},
+			size:   64,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b Buffer
+			test.pack(&b)
+			if b.Len() != test.size {
+				t.Errorf("packed %d bytes, want %d", b.Len(), test.size)
+			}
+			for offset, want := range test.floats {
+				if offset+4 > b.Len() {
+					t.Errorf("offset %d is past the end", offset)
+					continue
+				}
+				if got := floatAt(b.Bytes(), offset); got != want {
+					t.Errorf("at offset %d: got %g, want %g", offset, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBoolAndInt(t *testing.T) {
+	var b Buffer
+	b.Bool(true)
+	b.Bool(false)
+	b.Int(-2)
+	data := b.Bytes()
+	if got := binary.LittleEndian.Uint32(data[0:]); got != 1 {
+		t.Errorf("true packed as %d", got)
+	}
+	if got := binary.LittleEndian.Uint32(data[4:]); got != 0 {
+		t.Errorf("false packed as %d", got)
+	}
+	if got := int32(binary.LittleEndian.Uint32(data[8:])); got != -2 {
+		t.Errorf("-2 packed as %d", got)
+	}
+}
+
+func TestReset(t *testing.T) {
+	var b Buffer
+	b.Mat4(mgl.Ident4())
+	b.Reset()
+	b.Float(1)
+	if b.Len() != 4 {
+		t.Errorf("packed %d bytes after Reset, want 4", b.Len())
+	}
+}